@@ -28,46 +28,67 @@ var (
 	ErrAppServerShutdownintervalEnvInvalid = errors.New(APP_SERVER_SHUTDOWNINTERVAL_ENV)
 )
 
-func LoadConfig() (Config, error) {
+// LoadConfig reads the config from the environment.
+func LoadConfig(opts ...LoadOption) (Config, error) {
+	return LoadConfigFrom(os.LookupEnv, opts...)
+}
+
+// LoadConfigFromMap reads the config from m instead of the
+// environment.
+func LoadConfigFromMap(m map[string]string, opts ...LoadOption) (Config, error) {
+	return LoadConfigFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadConfigFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadConfigFrom(lookup func(string) (string, bool), opts ...LoadOption) (Config, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var config Config
 	var missingVars []error
 	var formatVars []error
-	val_Apikey, ok := os.LookupEnv(APP_APIKEY_ENV)
+	val_Apikey, ok := lookup(APP_APIKEY_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrAppApikeyEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Apikey", EnvVar: APP_APIKEY_ENV, Kind: "missing", sentinel: ErrAppApikeyEnvMissing})
 	} else {
 		config.Apikey = val_Apikey
 	}
-	val_Loglevel, ok := os.LookupEnv(APP_LOGLEVEL_ENV)
+	val_Loglevel, ok := lookup(APP_LOGLEVEL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrAppLoglevelEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Loglevel", EnvVar: APP_LOGLEVEL_ENV, Kind: "missing", sentinel: ErrAppLoglevelEnvMissing})
 	} else {
 		config.Loglevel = val_Loglevel
 	}
-	val_Server_Host, ok := os.LookupEnv(APP_SERVER_HOST_ENV)
+	val_Server_Host, ok := lookup(APP_SERVER_HOST_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrAppServerHostEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Server.Host", EnvVar: APP_SERVER_HOST_ENV, Kind: "missing", sentinel: ErrAppServerHostEnvMissing})
 	} else {
 		config.Server.Host = val_Server_Host
 	}
-	val_Server_Port, ok := os.LookupEnv(APP_SERVER_PORT_ENV)
+	val_Server_Port, ok := lookup(APP_SERVER_PORT_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrAppServerPortEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Server.Port", EnvVar: APP_SERVER_PORT_ENV, Kind: "missing", sentinel: ErrAppServerPortEnvMissing})
 	} else {
 		parsed, err := strconv.Atoi(val_Server_Port)
 		if err != nil {
-			formatVars = append(formatVars, ErrAppServerPortEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Server.Port", APP_SERVER_PORT_ENV, ErrAppServerPortEnvInvalid, val_Server_Port, false, err))
 		} else {
 			config.Server.Port = parsed
 		}
 	}
-	val_Server_ShutdownInterval, ok := os.LookupEnv(APP_SERVER_SHUTDOWNINTERVAL_ENV)
+	val_Server_ShutdownInterval, ok := lookup(APP_SERVER_SHUTDOWNINTERVAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrAppServerShutdownintervalEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Server.ShutdownInterval", EnvVar: APP_SERVER_SHUTDOWNINTERVAL_ENV, Kind: "missing", sentinel: ErrAppServerShutdownintervalEnvMissing})
 	} else {
 		parsed, err := time.ParseDuration(val_Server_ShutdownInterval)
 		if err != nil {
-			formatVars = append(formatVars, ErrAppServerShutdownintervalEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Server.ShutdownInterval", APP_SERVER_SHUTDOWNINTERVAL_ENV, ErrAppServerShutdownintervalEnvInvalid, val_Server_ShutdownInterval, false, err))
 		} else {
 			config.Server.ShutdownInterval = parsed
 		}
@@ -87,6 +108,79 @@ func LoadConfig() (Config, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -96,14 +190,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -115,12 +202,31 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return fe
 }
@@ -41,95 +41,116 @@ var (
 	ErrNesAgeEnvInvalid      = errors.New(_NES_AGE_ENV)
 )
 
-func LoadConfig() (Config, error) {
+// LoadConfig reads the config from the environment.
+func LoadConfig(opts ...LoadOption) (Config, error) {
+	return LoadConfigFrom(os.LookupEnv, opts...)
+}
+
+// LoadConfigFromMap reads the config from m instead of the
+// environment.
+func LoadConfigFromMap(m map[string]string, opts ...LoadOption) (Config, error) {
+	return LoadConfigFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadConfigFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadConfigFrom(lookup func(string) (string, bool), opts ...LoadOption) (Config, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var config Config
 	var missingVars []error
 	var formatVars []error
-	val_HddSyncPath, ok := os.LookupEnv(_HDDSYNCPATH_ENV)
+	val_HddSyncPath, ok := lookup(_HDDSYNCPATH_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrHddsyncpathEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "HddSyncPath", EnvVar: _HDDSYNCPATH_ENV, Kind: "missing", sentinel: ErrHddsyncpathEnvMissing})
 	} else {
 		config.HddSyncPath = val_HddSyncPath
 	}
-	val_DryRun, ok := os.LookupEnv(_DRYRUN_ENV)
+	val_DryRun, ok := lookup(_DRYRUN_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrDryrunEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "DryRun", EnvVar: _DRYRUN_ENV, Kind: "missing", sentinel: ErrDryrunEnvMissing})
 	} else {
 		parsed, err := strconv.ParseBool(val_DryRun)
 		if err != nil {
-			formatVars = append(formatVars, ErrDryrunEnvInvalid)
+			formatVars = append(formatVars, newFieldError("DryRun", _DRYRUN_ENV, ErrDryrunEnvInvalid, val_DryRun, false, err))
 		} else {
 			config.DryRun = parsed
 		}
 	}
-	val_Lol, ok := os.LookupEnv(_LOL_ENV)
+	val_Lol, ok := lookup(_LOL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrLolEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Lol", EnvVar: _LOL_ENV, Kind: "missing", sentinel: ErrLolEnvMissing})
 	} else {
 		parsed, err := strconv.ParseBool(val_Lol)
 		if err != nil {
-			formatVars = append(formatVars, ErrLolEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Lol", _LOL_ENV, ErrLolEnvInvalid, val_Lol, false, err))
 		} else {
 			config.Lol = parsed
 		}
 	}
-	val_Timeout, ok := os.LookupEnv(_TIMEOUT_ENV)
+	val_Timeout, ok := lookup(_TIMEOUT_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTimeoutEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Timeout", EnvVar: _TIMEOUT_ENV, Kind: "missing", sentinel: ErrTimeoutEnvMissing})
 	} else {
 		parsed, err := time.ParseDuration(val_Timeout)
 		if err != nil {
-			formatVars = append(formatVars, ErrTimeoutEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Timeout", _TIMEOUT_ENV, ErrTimeoutEnvInvalid, val_Timeout, false, err))
 		} else {
 			config.Timeout = parsed
 		}
 	}
-	val_Port, ok := os.LookupEnv(_PORT_ENV)
+	val_Port, ok := lookup(_PORT_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrPortEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Port", EnvVar: _PORT_ENV, Kind: "missing", sentinel: ErrPortEnvMissing})
 	} else {
 		parsed, err := strconv.Atoi(val_Port)
 		if err != nil {
-			formatVars = append(formatVars, ErrPortEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Port", _PORT_ENV, ErrPortEnvInvalid, val_Port, false, err))
 		} else {
 			config.Port = parsed
 		}
 	}
-	val_Port32, ok := os.LookupEnv(_PORT32_ENV)
+	val_Port32, ok := lookup(_PORT32_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrPort32EnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Port32", EnvVar: _PORT32_ENV, Kind: "missing", sentinel: ErrPort32EnvMissing})
 	} else {
 		parsed, err := strconv.ParseUint(val_Port32, 10, 32)
 		if err != nil {
-			formatVars = append(formatVars, ErrPort32EnvInvalid)
+			formatVars = append(formatVars, newFieldError("Port32", _PORT32_ENV, ErrPort32EnvInvalid, val_Port32, false, err))
 		} else {
 			config.Port32 = uint32(parsed)
 		}
 	}
-	val_Port16, ok := os.LookupEnv(_PORT16_ENV)
+	val_Port16, ok := lookup(_PORT16_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrPort16EnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Port16", EnvVar: _PORT16_ENV, Kind: "missing", sentinel: ErrPort16EnvMissing})
 	} else {
 		parsed, err := strconv.ParseInt(val_Port16, 10, 16)
 		if err != nil {
-			formatVars = append(formatVars, ErrPort16EnvInvalid)
+			formatVars = append(formatVars, newFieldError("Port16", _PORT16_ENV, ErrPort16EnvInvalid, val_Port16, false, err))
 		} else {
 			config.Port16 = int16(parsed)
 		}
 	}
-	val_Nes_Name, ok := os.LookupEnv(_NES_NAME_ENV)
+	val_Nes_Name, ok := lookup(_NES_NAME_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrNesNameEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Nes.Name", EnvVar: _NES_NAME_ENV, Kind: "missing", sentinel: ErrNesNameEnvMissing})
 	} else {
 		config.Nes.Name = val_Nes_Name
 	}
-	val_Nes_Age, ok := os.LookupEnv(_NES_AGE_ENV)
+	val_Nes_Age, ok := lookup(_NES_AGE_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrNesAgeEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Nes.Age", EnvVar: _NES_AGE_ENV, Kind: "missing", sentinel: ErrNesAgeEnvMissing})
 	} else {
 		parsed, err := strconv.Atoi(val_Nes_Age)
 		if err != nil {
-			formatVars = append(formatVars, ErrNesAgeEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Nes.Age", _NES_AGE_ENV, ErrNesAgeEnvInvalid, val_Nes_Age, false, err))
 		} else {
 			config.Nes.Age = parsed
 		}
@@ -149,6 +170,79 @@ func LoadConfig() (Config, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -158,14 +252,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -177,12 +264,31 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return fe
 }
@@ -41,95 +41,116 @@ var (
 	ErrMyappNeAgeEnvInvalid       = errors.New(MYAPP_NE_AGE_ENV)
 )
 
-func LoadMyConfig() (MyConfig, error) {
+// LoadMyConfig reads the config from the environment.
+func LoadMyConfig(opts ...LoadOption) (MyConfig, error) {
+	return LoadMyConfigFrom(os.LookupEnv, opts...)
+}
+
+// LoadMyConfigFromMap reads the config from m instead of the
+// environment.
+func LoadMyConfigFromMap(m map[string]string, opts ...LoadOption) (MyConfig, error) {
+	return LoadMyConfigFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadMyConfigFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadMyConfigFrom(lookup func(string) (string, bool), opts ...LoadOption) (MyConfig, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var config MyConfig
 	var missingVars []error
 	var formatVars []error
-	val_HddSyncPath, ok := os.LookupEnv(MYAPP_HDDSYNCPATH_ENV)
+	val_HddSyncPath, ok := lookup(MYAPP_HDDSYNCPATH_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappHddsyncpathEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "HddSyncPath", EnvVar: MYAPP_HDDSYNCPATH_ENV, Kind: "missing", sentinel: ErrMyappHddsyncpathEnvMissing})
 	} else {
 		config.HddSyncPath = val_HddSyncPath
 	}
-	val_DryRun, ok := os.LookupEnv(MYAPP_DRYRUN_ENV)
+	val_DryRun, ok := lookup(MYAPP_DRYRUN_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappDryrunEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "DryRun", EnvVar: MYAPP_DRYRUN_ENV, Kind: "missing", sentinel: ErrMyappDryrunEnvMissing})
 	} else {
 		parsed, err := strconv.ParseBool(val_DryRun)
 		if err != nil {
-			formatVars = append(formatVars, ErrMyappDryrunEnvInvalid)
+			formatVars = append(formatVars, newFieldError("DryRun", MYAPP_DRYRUN_ENV, ErrMyappDryrunEnvInvalid, val_DryRun, false, err))
 		} else {
 			config.DryRun = parsed
 		}
 	}
-	val_Lol, ok := os.LookupEnv(MYAPP_LOL_ENV)
+	val_Lol, ok := lookup(MYAPP_LOL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappLolEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Lol", EnvVar: MYAPP_LOL_ENV, Kind: "missing", sentinel: ErrMyappLolEnvMissing})
 	} else {
 		parsed, err := strconv.ParseBool(val_Lol)
 		if err != nil {
-			formatVars = append(formatVars, ErrMyappLolEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Lol", MYAPP_LOL_ENV, ErrMyappLolEnvInvalid, val_Lol, false, err))
 		} else {
 			config.Lol = parsed
 		}
 	}
-	val_Timeout, ok := os.LookupEnv(MYAPP_TIMEOUT_ENV)
+	val_Timeout, ok := lookup(MYAPP_TIMEOUT_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappTimeoutEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Timeout", EnvVar: MYAPP_TIMEOUT_ENV, Kind: "missing", sentinel: ErrMyappTimeoutEnvMissing})
 	} else {
 		parsed, err := time.ParseDuration(val_Timeout)
 		if err != nil {
-			formatVars = append(formatVars, ErrMyappTimeoutEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Timeout", MYAPP_TIMEOUT_ENV, ErrMyappTimeoutEnvInvalid, val_Timeout, false, err))
 		} else {
 			config.Timeout = parsed
 		}
 	}
-	val_Port, ok := os.LookupEnv(MYAPP_PORT_ENV)
+	val_Port, ok := lookup(MYAPP_PORT_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappPortEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Port", EnvVar: MYAPP_PORT_ENV, Kind: "missing", sentinel: ErrMyappPortEnvMissing})
 	} else {
 		parsed, err := strconv.Atoi(val_Port)
 		if err != nil {
-			formatVars = append(formatVars, ErrMyappPortEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Port", MYAPP_PORT_ENV, ErrMyappPortEnvInvalid, val_Port, false, err))
 		} else {
 			config.Port = parsed
 		}
 	}
-	val_Port32, ok := os.LookupEnv(MYAPP_PORT32_ENV)
+	val_Port32, ok := lookup(MYAPP_PORT32_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappPort32EnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Port32", EnvVar: MYAPP_PORT32_ENV, Kind: "missing", sentinel: ErrMyappPort32EnvMissing})
 	} else {
 		parsed, err := strconv.ParseUint(val_Port32, 10, 32)
 		if err != nil {
-			formatVars = append(formatVars, ErrMyappPort32EnvInvalid)
+			formatVars = append(formatVars, newFieldError("Port32", MYAPP_PORT32_ENV, ErrMyappPort32EnvInvalid, val_Port32, false, err))
 		} else {
 			config.Port32 = uint32(parsed)
 		}
 	}
-	val_Port16, ok := os.LookupEnv(MYAPP_PORT16_ENV)
+	val_Port16, ok := lookup(MYAPP_PORT16_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappPort16EnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Port16", EnvVar: MYAPP_PORT16_ENV, Kind: "missing", sentinel: ErrMyappPort16EnvMissing})
 	} else {
 		parsed, err := strconv.ParseInt(val_Port16, 10, 16)
 		if err != nil {
-			formatVars = append(formatVars, ErrMyappPort16EnvInvalid)
+			formatVars = append(formatVars, newFieldError("Port16", MYAPP_PORT16_ENV, ErrMyappPort16EnvInvalid, val_Port16, false, err))
 		} else {
 			config.Port16 = int16(parsed)
 		}
 	}
-	val_Ne_Name, ok := os.LookupEnv(MYAPP_NE_NAME_ENV)
+	val_Ne_Name, ok := lookup(MYAPP_NE_NAME_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappNeNameEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Ne.Name", EnvVar: MYAPP_NE_NAME_ENV, Kind: "missing", sentinel: ErrMyappNeNameEnvMissing})
 	} else {
 		config.Ne.Name = val_Ne_Name
 	}
-	val_Ne_Age, ok := os.LookupEnv(MYAPP_NE_AGE_ENV)
+	val_Ne_Age, ok := lookup(MYAPP_NE_AGE_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrMyappNeAgeEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Ne.Age", EnvVar: MYAPP_NE_AGE_ENV, Kind: "missing", sentinel: ErrMyappNeAgeEnvMissing})
 	} else {
 		parsed, err := strconv.Atoi(val_Ne_Age)
 		if err != nil {
-			formatVars = append(formatVars, ErrMyappNeAgeEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Ne.Age", MYAPP_NE_AGE_ENV, ErrMyappNeAgeEnvInvalid, val_Ne_Age, false, err))
 		} else {
 			config.Ne.Age = parsed
 		}
@@ -149,6 +170,79 @@ func LoadMyConfig() (MyConfig, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -158,14 +252,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -177,12 +264,31 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return fe
 }
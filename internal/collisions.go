@@ -0,0 +1,157 @@
+package genconfig
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// detectCollisions reports every pair of fields that would end up sharing an
+// env var, an error variable, an env constant, an assignment identifier or a
+// flag name. Since names are derived by dropping punctuation and changing
+// case, e.g. My_Field and a nested My.Field, such pairs are easy to create by
+// accident and would otherwise produce code that either does not compile or
+// silently reads the same variable twice. With caseInsensitive, env vars that
+// only differ by case collide as well.
+func detectCollisions(fields []TemplateData, caseInsensitive bool) error {
+	var errs []error
+	// a pair of fields usually collides on several names at once, only the
+	// first one is reported
+	reported := map[[2]string]struct{}{}
+	check := func(kind string, seen map[string]TemplateData, name string, field TemplateData) {
+		if name == "" {
+			return
+		}
+		if other, ok := seen[name]; ok {
+			pair := [2]string{other.Name, field.Name}
+			if _, ok := reported[pair]; ok {
+				return
+			}
+			reported[pair] = struct{}{}
+			errs = append(errs, fmt.Errorf("%s %s is used by both %s (%s) and %s (%s)", kind, name, other.Name, other.Position, field.Name, field.Position))
+			return
+		}
+		seen[name] = field
+	}
+
+	envVars := map[string]TemplateData{}
+	envConsts := map[string]TemplateData{}
+	errVars := map[string]TemplateData{}
+	assignments := map[string]TemplateData{}
+	flags := map[string]TemplateData{}
+	envVarKey := func(name string) string {
+		if caseInsensitive {
+			return strings.ToUpper(name)
+		}
+		return name
+	}
+	for _, field := range fields {
+		check("env var", envVars, envVarKey(field.EnvVar), field)
+		for _, alias := range field.Aliases {
+			check("env var", envVars, envVarKey(alias), field)
+		}
+		check("env constant", envConsts, field.EnvConst, field)
+		check("error variable", errVars, field.MissingErrVar, field)
+		check("error variable", errVars, field.InvalidErrVar, field)
+		check("error variable", errVars, field.ConflictErrVar, field)
+		check("error variable", errVars, field.AmbiguousErrVar, field)
+		check("identifier", assignments, field.AssignmentName, field)
+		check("flag", flags, field.FlagName, field)
+	}
+	return errors.Join(errs...)
+}
+
+// detectPackageCollisions reports the top-level names of a generated file that
+// other files of its package already declare. Two configs generated with the
+// same prefix, for example, both declare the env constant and the sentinel
+// errors of a field they share, which only fails once the package is
+// compiled. The generated files themselves are skipped, since they are about
+// to be replaced.
+func detectPackageCollisions(generated []byte, outputFiles ...string) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", generated, parser.SkipObjectResolution)
+	if err != nil {
+		return fmt.Errorf("could not parse generated file: %w", err)
+	}
+	declared := map[string]struct{}{}
+	for _, name := range topLevelNames(file) {
+		declared[name.Name] = struct{}{}
+	}
+
+	dir := filepath.Dir(outputFiles[0])
+	skip := map[string]struct{}{}
+	for _, f := range outputFiles {
+		if abs, err := filepath.Abs(f); err == nil {
+			skip[abs] = struct{}{}
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read output directory: %w", err)
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if abs, err := filepath.Abs(path); err == nil {
+			if _, ok := skip[abs]; ok {
+				continue
+			}
+		}
+		// a file that does not parse is left for the compiler to report
+		other, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil || other.Name.Name != file.Name.Name {
+			continue
+		}
+		for _, ident := range topLevelNames(other) {
+			if _, ok := declared[ident.Name]; ok {
+				errs = append(errs, fmt.Errorf("%s is already declared in %s", ident.Name, fset.Position(ident.Pos())))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// topLevelNames returns the identifiers a file declares in its package scope.
+// Methods, init functions and blank identifiers are left out since they can
+// be declared more than once.
+func topLevelNames(file *ast.File) []*ast.Ident {
+	var names []*ast.Ident
+	add := func(ident *ast.Ident) {
+		if ident.Name != "_" && ident.Name != "init" {
+			names = append(names, ident)
+		}
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				add(d.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name)
+				case *ast.ValueSpec:
+					for _, ident := range s.Names {
+						add(ident)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,142 @@
+package genconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Condition is a requiredif, requiredwith or excludes tag resolved against
+// the other fields of the config.
+type Condition struct {
+	Cond        string // Go expression that is true when the condition is violated
+	Rule        string // requiredif, requiredwith or excludes
+	Field       string
+	EnvConst    string
+	OtherField  string
+	OtherConst  string
+	OtherValue  string // value of OtherField that makes Field required, for requiredif
+	presenceFor []int  // indexes of the fields whose presence Cond reads
+}
+
+// resolveConditions resolves the conditional tags of every field and marks
+// the fields whose presence the conditions read. A referenced field is
+// looked up in the section of the tagged field first, then from the root.
+func resolveConditions(fields []TemplateData) ([]Condition, error) {
+	byName := make(map[string]int, len(fields))
+	for i, f := range fields {
+		byName[f.Name] = i
+	}
+	resolve := func(self int, tag string, ref string) (int, error) {
+		ref = strings.TrimSpace(ref)
+		name := fields[self].Name
+		candidates := []string{ref}
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			candidates = []string{name[:dot+1] + ref, ref}
+		}
+		for _, candidate := range candidates {
+			if i, ok := byName[candidate]; ok {
+				if i == self {
+					return 0, fmt.Errorf("%s tag on %s (%s) refers to the field itself", tag, name, fields[self].Position)
+				}
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("%s tag on %s (%s) refers to unknown field %s", tag, name, fields[self].Position, ref)
+	}
+
+	var conditions []Condition
+	for self, f := range fields {
+		if f.RequiredIf != "" {
+			ref, value, ok := strings.Cut(f.RequiredIf, "=")
+			if !ok {
+				return nil, fmt.Errorf("requiredif tag on %s (%s) must have the form Field=value", f.Name, f.Position)
+			}
+			other, err := resolve(self, "requiredif", ref)
+			if err != nil {
+				return nil, err
+			}
+			lit, err := conditionLiteral(fields[other], value)
+			if err != nil {
+				return nil, fmt.Errorf("requiredif tag on %s (%s): %w", f.Name, f.Position, err)
+			}
+			cond := "config." + fields[other].Name + " == " + lit
+			switch lit {
+			case "true":
+				cond = "config." + fields[other].Name
+			case "false":
+				cond = "!config." + fields[other].Name
+			}
+			conditions = append(conditions, Condition{
+				Cond:        cond + " && !" + presenceVar(f),
+				Rule:        "requiredif",
+				OtherValue:  value,
+				presenceFor: []int{self},
+			}.between(f, fields[other]))
+		}
+		for _, ref := range f.RequiredWith {
+			other, err := resolve(self, "requiredwith", ref)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, Condition{
+				Cond:        presenceVar(fields[other]) + " && !" + presenceVar(f),
+				Rule:        "requiredwith",
+				presenceFor: []int{self, other},
+			}.between(f, fields[other]))
+		}
+		for _, ref := range f.Excludes {
+			other, err := resolve(self, "excludes", ref)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, Condition{
+				Cond:        presenceVar(f) + " && " + presenceVar(fields[other]),
+				Rule:        "excludes",
+				presenceFor: []int{self, other},
+			}.between(f, fields[other]))
+		}
+	}
+	for _, c := range conditions {
+		for _, i := range c.presenceFor {
+			fields[i].PresenceVar = presenceVar(fields[i])
+		}
+	}
+	return conditions, nil
+}
+
+func (c Condition) between(field, other TemplateData) Condition {
+	c.Field, c.EnvConst = field.Name, field.EnvConst
+	c.OtherField, c.OtherConst = other.Name, other.EnvConst
+	return c
+}
+
+// presenceVar is the identifier recording whether the variable of the field
+// was set when it was loaded. Defaults do not count as set.
+func presenceVar(f TemplateData) string {
+	return "set_" + strings.TrimPrefix(f.AssignmentName, "val_")
+}
+
+// conditionLiteral returns value as a Go literal of the type of field, so
+// that requiredif compares parsed values rather than their spelling.
+func conditionLiteral(field TemplateData, value string) (string, error) {
+	switch field.GoType {
+	case "string":
+		return strconv.Quote(value), nil
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a valid bool for %s", value, field.Name)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	parse := numericBoundParser(field.GoType, field.BitSize, field.ParseFunc)
+	if parse == nil {
+		return "", fmt.Errorf("comparing %s fields is not supported", field.GoType)
+	}
+	lit, _, err := parse(value)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a valid %s for %s", value, field.GoType, field.Name)
+	}
+	return lit, nil
+}
@@ -8,9 +8,9 @@ import (
 	"go/format"
 	"go/parser"
 	"go/token"
+	"maps"
 	"os"
 	"path/filepath"
-	"reflect"
 	"slices"
 	"strings"
 	"text/template"
@@ -18,18 +18,41 @@ import (
 )
 
 type TemplateData struct {
-	PackageName    string
-	Name           string
-	AssignmentName string
-	EnvVar         string
-	ParseFunc      string
-	HasDefault     bool
-	DefaultRaw     string // raw string from default:"..." tag; used as fallback value
-	MissingErrVar  string // empty iff HasDefault
-	InvalidErrVar  string // empty iff !FormatErr
-	FormatErr      bool
-	BitSize        int    // used to determine how to call parseFunc
-	CastFunc       string // parseInt and parseUint return 64bit numbers, need to cast
+	PackageName     string
+	Name            string
+	AssignmentName  string
+	EnvVar          string
+	EnvConst        string // name of the generated constant holding EnvVar
+	ParseFunc       string
+	HasDefault      bool
+	DefaultRaw      string // raw string from default:"..." tag; used as fallback value
+	MissingErrVar   string // empty iff HasDefault
+	InvalidErrVar   string // empty iff !FormatErr
+	FormatErr       bool
+	BitSize         int              // used to determine how to call parseFunc
+	CastFunc        string           // parseInt and parseUint return 64bit numbers, need to cast
+	Normalize       []string         // functions applied to the raw value before parsing, in order
+	Position        token.Position   // location of the field in the input file, used in generator errors
+	Aliases         []string         // deprecated env var names tried in order after EnvVar
+	ConflictErrVar  string           // empty iff no Aliases
+	AmbiguousErrVar string           // empty iff the lookup is case-sensitive
+	DefaultExpands  bool             // DefaultRaw contains ${...} or $$ and is expanded at load time
+	ProfileDefaults []ProfileDefault // defaults that replace DefaultRaw when their profile is selected
+	Validations     []Validation     // checks on the parsed value, from min, max, minlen, maxlen and pattern tags
+	Pattern         string           // pattern:"..." tag, empty if not set
+	PatternVar      string           // package-level variable holding the compiled Pattern
+	EmptyIsUnset    bool             // an empty value is treated as unset
+	TrimsSpace      bool             // Normalize trims spaces, so a blank value counts as empty
+	SecretRefs      bool             // the value may be a resolved secret reference, redacted in errors
+	Optional        bool             // an unset value leaves the zero value instead of being missing
+	GoType          string           // type of the field as written, e.g. time.Duration
+	RequiredIf      string           // requiredif:"Field=value" tag, checked after loading
+	RequiredWith    []string         // requiredwith tag, checked after loading
+	Excludes        []string         // excludes tag, checked after loading
+	PresenceVar     string           // identifier recording whether the field is set, when a condition reads it
+	TypeHint        string           // type with an example value, shown by FormatConfigError
+	FlagName        string           // lowercased field path, e.g. server.port
+	Doc             string           // field doc comment on a single line, used as flag help
 }
 
 func printformat(debug bool, format string, a ...any) {
@@ -85,21 +108,92 @@ func createOutputDotenvFile(outputGeneratedDotenvFile string) (*os.File, error)
 	return generatedFile, nil
 }
 
+// Options holds the generator settings that are optional and therefore not
+// positional arguments of GenerateConfigLoader. The zero value keeps the
+// default behavior.
+type Options struct {
+	// Naming is the strategy used to build env var names, one of NamingFlat,
+	// NamingSnake or NamingKebab. Empty means NamingFlat.
+	Naming string
+	// Separator is placed between the segments of a field path, e.g. "__"
+	// for APP__SERVER__PORT. Empty means the default of the naming
+	// strategy, which is "-" for NamingKebab and "_" otherwise.
+	Separator string
+	// CaseInsensitive makes the generated loader resolve env vars regardless
+	// of case, reporting variables that are set more than once with
+	// different case as ambiguous.
+	CaseInsensitive bool
+	// EmptyIsUnset treats env vars that are set to an empty string as unset
+	// for every field, as the required:"nonempty" tag does for one field.
+	EmptyIsUnset bool
+	// Dotenv generates the WithDotenv load option, which layers dotenv files
+	// under the environment.
+	Dotenv bool
+	// JSONFile generates Load<Struct>WithFile and the WithConfigFile load
+	// option, which layer a JSON config file under the environment.
+	JSONFile bool
+	// ConfigDir generates the WithConfigDir load option, which layers a
+	// directory of files, such as a mounted ConfigMap, under the environment.
+	ConfigDir bool
+	// Flags generates Register<Struct>Flags, Load<Struct>WithFlags and the
+	// WithFlags load option, which give flags precedence over every other
+	// source.
+	Flags bool
+	// Secrets resolves secretref:// values through a SecretResolver and
+	// generates the WithSecretResolver and WithSecretTimeout load options.
+	Secrets bool
+	// Interpolation expands ${VAR}, ${VAR:-fallback} and $$ in values and
+	// defaults when the config is loaded. Without it, values are used as
+	// they are, so a literal $ needs no escaping.
+	Interpolation bool
+	// ErrorReport generates FormatConfigError, which formats load errors as
+	// a table with the type, default and description of each field.
+	ErrorReport bool
+	// RuntimeFile is the file that receives the declarations shared by every
+	// generated config, such as LoadOption and MissingEnvVarsError, instead
+	// of the config loader. Generating several configs of one package with
+	// the same RuntimeFile lets them be used side by side. Their constants
+	// and errors are then named after the config struct.
+	RuntimeFile string
+}
+
 func GenerateConfigLoader(projectPrefix, configStructName, inputFile, outputGeneratedConfigFile, outputDotenv string, testBuildTag string, debug bool) error {
+	return GenerateConfigLoaderWithOptions(projectPrefix, configStructName, inputFile, outputGeneratedConfigFile, outputDotenv, testBuildTag, debug, Options{})
+}
+
+func GenerateConfigLoaderWithOptions(projectPrefix, configStructName, inputFile, outputGeneratedConfigFile, outputDotenv string, testBuildTag string, debug bool, opts Options) error {
 
 	printformat(debug, "using project name prefix %s\n", projectPrefix)
 
-	generatedFile, err := createOutputFile(outputGeneratedConfigFile)
-	if err != nil {
-		return fmt.Errorf("could not create output config loader file: %w", err)
+	if opts.Naming == "" {
+		opts.Naming = NamingFlat
+	}
+	if !isValidNaming(opts.Naming) {
+		return fmt.Errorf("unsupported naming strategy %q", opts.Naming)
+	}
+	if opts.Separator == "" {
+		opts.Separator = defaultSeparator(opts.Naming)
+	}
+	if !isValidSeparator(opts.Separator) {
+		return fmt.Errorf("unsupported separator %q, only '_', '-' and '.' are allowed", opts.Separator)
+	}
+	naming := envNaming{strategy: opts.Naming, separator: opts.Separator}
+	if opts.RuntimeFile != "" {
+		// the configs sharing a runtime live in one package and may read
+		// the same env vars, so their constants and errors are told apart
+		// by the config struct
+		naming.scope = configStructName
 	}
-	defer generatedFile.Close()
 
 	outputImports := setupImportsAlwaysNeeded()
+	if opts.Flags {
+		// Register<Struct>Flags and Load<Struct>WithFlags
+		outputImports[`"flag"`] = struct{}{}
+	}
 
 	// Parse config.go
 	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, inputFile, nil, parser.AllErrors)
+	node, err := parser.ParseFile(fset, inputFile, nil, parser.AllErrors|parser.ParseComments)
 	if err != nil {
 		return fmt.Errorf("could not parse file with config struct: %w", err)
 	}
@@ -119,31 +213,164 @@ func GenerateConfigLoader(projectPrefix, configStructName, inputFile, outputGene
 
 	configTypeDefinition := allTopLevelStructDefinitions[configStructName]
 	parentNames := []string{}
+	envParentNames := []string{}
 
-	insertTemplateDataEntryForStruct(configTypeDefinition, configStructName, &parentNames, projectPrefix, outputImports, &fields, allTopLevelStructDefinitions, debug)
+	insertTemplateDataEntryForStruct(fset, configTypeDefinition, configStructName, &parentNames, &envParentNames, projectPrefix, naming, opts.CaseInsensitive, outputImports, &fields, allTopLevelStructDefinitions, debug)
 
-	importList := generateImportsListAsTemplateString(outputImports)
+	if opts.EmptyIsUnset {
+		for i := range fields {
+			fields[i].EmptyIsUnset = true
+		}
+	}
+	if opts.Secrets {
+		for i := range fields {
+			fields[i].SecretRefs = true
+		}
+	}
+	if opts.Interpolation {
+		for i := range fields {
+			fields[i].DefaultExpands = strings.Contains(fields[i].DefaultRaw, "$")
+		}
+	}
 
-	var buf bytes.Buffer
-	goTemplate.Execute(&buf, struct {
-		Prefix       string
-		StructName   string
-		Fields       []TemplateData
-		TestBuildTag string
-		ImportList   string
-		PackageName  string
-	}{
-		Prefix:       projectPrefix,
-		StructName:   configStructName,
-		Fields:       fields,
-		TestBuildTag: testBuildTag,
-		ImportList:   importList,
-		PackageName:  packageName,
-	})
-	formatted, err := format.Source(buf.Bytes())
+	// Check before writing anything, so that a conflicting config never
+	// leaves a half-updated or non-compiling loader behind.
+	if err := detectCollisions(fields, opts.CaseInsensitive); err != nil {
+		return fmt.Errorf("conflicting names in config: %w", err)
+	}
+
+	conditions, err := resolveConditions(fields)
 	if err != nil {
-		return fmt.Errorf("could not format generated code: %w", err)
+		return fmt.Errorf("invalid conditional requirement: %w", err)
+	}
+	validateCalls := collectValidateCalls(configStructName, allTopLevelStructDefinitions, findValidateMethods(node, inputFile, outputGeneratedConfigFile, debug))
+	profiles := collectProfiles(fields)
+	profileEnvVar := getEnvKey([]string{projectPrefix, profileEnvName}, naming)
+	profileEnvConst := naming.constName(profileEnvVar)
+	if len(profiles) > 0 {
+		if err := checkProfileEnv(fields, profileEnvVar, profileEnvConst); err != nil {
+			return fmt.Errorf("conflicting names in config: %w", err)
+		}
+	}
+
+	hasValidations := len(conditions) > 0 || slices.ContainsFunc(fields, func(f TemplateData) bool { return len(f.Validations) > 0 })
+	hasAliases := slices.ContainsFunc(fields, func(f TemplateData) bool { return len(f.Aliases) > 0 })
+	helperNames := requiredHelpers(fields)
+	if opts.Dotenv {
+		helperNames = append(helperNames, "readDotenvFiles")
+	}
+	if opts.JSONFile {
+		helperNames = append(helperNames, "readConfigFile")
+	}
+	if opts.ConfigDir {
+		helperNames = append(helperNames, "readConfigDir")
+	}
+	if opts.Secrets {
+		helperNames = append(helperNames, "resolveSecrets")
 	}
+	if opts.Interpolation {
+		helperNames = append(helperNames, "newExpander")
+	}
+	if opts.CaseInsensitive {
+		helperNames = append(helperNames, "newEnvIndex")
+	}
+	if opts.Dotenv || opts.JSONFile || opts.ConfigDir || opts.Flags {
+		helperNames = append(helperNames, "layerLookups")
+	}
+
+	data := loaderData{
+		Prefix:           projectPrefix,
+		StructName:       configStructName,
+		Fields:           fields,
+		TestBuildTag:     testBuildTag,
+		PackageName:      packageName,
+		HasAliases:       hasAliases,
+		HasFormatErrs:    slices.ContainsFunc(fields, func(f TemplateData) bool { return f.FormatErr }),
+		HasValidations:   hasValidations,
+		HasConditions:    len(conditions) > 0,
+		HasValidateCalls: len(validateCalls) > 0,
+		HasProfiles:      len(profiles) > 0,
+		ValidateCalls:    validateCalls,
+		Conditions:       conditions,
+		Profiles:         profiles,
+		ProfileEnvVar:    profileEnvVar,
+		ProfileEnvConst:  profileEnvConst,
+		CaseInsensitive:  opts.CaseInsensitive,
+		Dotenv:           opts.Dotenv,
+		JSONFile:         opts.JSONFile,
+		ConfigDir:        opts.ConfigDir,
+		Flags:            opts.Flags,
+		Secrets:          opts.Secrets,
+		Interpolation:    opts.Interpolation,
+		ErrorReport:      opts.ErrorReport,
+		SharedRuntime:    opts.RuntimeFile != "",
+	}
+	var runtime []byte
+	if !data.SharedRuntime {
+		data.Helpers = collectHelpers(helperNames, outputImports)
+		registerRuntimeImports(data, outputImports)
+	} else {
+		// The runtime holds everything any config may need, so that every
+		// config of the package writes the same file.
+		runtimeImports := setupImportsAlwaysNeeded()
+		runtimeData := loaderData{
+			TestBuildTag:     testBuildTag,
+			PackageName:      packageName,
+			Helpers:          collectHelpers(slices.Collect(maps.Keys(generatedHelpers)), runtimeImports),
+			HasAliases:       true,
+			HasValidations:   true,
+			HasConditions:    true,
+			HasValidateCalls: true,
+			HasProfiles:      true,
+			CaseInsensitive:  true,
+			Dotenv:           true,
+			JSONFile:         true,
+			ConfigDir:        true,
+			Flags:            true,
+			Secrets:          true,
+			Interpolation:    true,
+			ErrorReport:      true,
+		}
+		registerRuntimeImports(runtimeData, runtimeImports)
+		runtime, err = renderLoader("runtimefile", runtimeData, runtimeImports)
+		if err != nil {
+			return err
+		}
+	}
+	formatted, err := renderLoader("config", data, outputImports)
+	if err != nil {
+		return err
+	}
+
+	// Other configs of the package are only known once the loader is
+	// rendered, but are checked before anything is written as well.
+	outputFiles := []string{outputGeneratedConfigFile}
+	if opts.RuntimeFile != "" {
+		outputFiles = append(outputFiles, opts.RuntimeFile)
+	}
+	if err := detectPackageCollisions(formatted, outputFiles...); err != nil {
+		return fmt.Errorf("conflicting names in package: %w", err)
+	}
+	if runtime != nil {
+		if err := detectPackageCollisions(runtime, outputFiles...); err != nil {
+			return fmt.Errorf("conflicting names in package: %w", err)
+		}
+	}
+
+	if runtime != nil {
+		runtimeFile, err := createOutputFile(opts.RuntimeFile)
+		if err != nil {
+			return fmt.Errorf("could not create output runtime file: %w", err)
+		}
+		defer runtimeFile.Close()
+		runtimeFile.Write(runtime)
+	}
+
+	generatedFile, err := createOutputFile(outputGeneratedConfigFile)
+	if err != nil {
+		return fmt.Errorf("could not create output config loader file: %w", err)
+	}
+	defer generatedFile.Close()
 	generatedFile.Write(formatted)
 
 	printline(debug, fields)
@@ -156,13 +383,70 @@ func GenerateConfigLoader(projectPrefix, configStructName, inputFile, outputGene
 		}
 		defer outEnv.Close()
 		for _, field := range fields {
-			fmt.Fprintf(outEnv, "%s='%s'\n", field.EnvVar, field.DefaultRaw)
+			fmt.Fprintf(outEnv, "%s=\"%s\"\n", field.EnvVar, dotenvEscaper.Replace(field.DefaultRaw))
 		}
 	}
 	return nil
 }
 
-func insertTemplateDataEntryForStruct(structDefinition *ast.StructType, structName string, parentNames *[]string, projectPrefix string, outputImports map[string]struct{}, templateData *[]TemplateData, allTopLevelStructDefinitions map[string]*ast.StructType, debug bool) {
+// dotenvEscaper escapes a double quoted value of the generated .env file the
+// way the dotenv parser of the loader reads it back. $ is kept as it is, so
+// that a default is interpolated the same whether it is read from the tag or
+// from the file.
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// loaderData is the data of the generated loader. The Has... fields select
+// the runtime declarations that are emitted.
+type loaderData struct {
+	Prefix           string
+	StructName       string
+	Fields           []TemplateData
+	TestBuildTag     string
+	ImportList       string
+	PackageName      string
+	Helpers          string
+	HasAliases       bool
+	HasFormatErrs    bool
+	HasValidations   bool
+	HasConditions    bool
+	HasValidateCalls bool
+	HasProfiles      bool
+	ValidateCalls    []ValidateCall
+	Conditions       []Condition
+	Profiles         []ProfileData
+	ProfileEnvVar    string
+	ProfileEnvConst  string
+	CaseInsensitive  bool
+	Dotenv           bool
+	JSONFile         bool
+	ConfigDir        bool
+	Flags            bool
+	Secrets          bool
+	Interpolation    bool
+	ErrorReport      bool
+	SharedRuntime    bool // the runtime declarations live in Options.RuntimeFile
+}
+
+// renderLoader executes the named template with imports and formats the
+// result.
+func renderLoader(name string, data loaderData, imports map[string]struct{}) ([]byte, error) {
+	data.ImportList = generateImportsListAsTemplateString(imports)
+	var buf bytes.Buffer
+	if err := goTemplate.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, fmt.Errorf("could not execute template: %w", err)
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("could not format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// insertTemplateDataEntryForStruct walks the struct definition and appends an
+// entry for every leaf field. parentNames holds the field names leading to
+// the struct and is used for Go identifiers, while envParentNames holds the
+// segments used for env var names, which differ when envprefix tags are set.
+func insertTemplateDataEntryForStruct(fset *token.FileSet, structDefinition *ast.StructType, structName string, parentNames *[]string, envParentNames *[]string, projectPrefix string, naming envNaming, caseInsensitive bool, outputImports map[string]struct{}, templateData *[]TemplateData, allTopLevelStructDefinitions map[string]*ast.StructType, debug bool) {
 
 	if structDefinition.Fields == nil {
 		return
@@ -184,60 +468,127 @@ func insertTemplateDataEntryForStruct(structDefinition *ast.StructType, structNa
 			typ := convertTypeIdentifierToString(f.Type)
 			printline(debug, "identifier type", typ, "field name", n.Name)
 
-			// parse default:"..." struct tag
-			var hasDefault bool
-			var defaultRaw string
-			if f.Tag != nil {
-				tag := reflect.StructTag(strings.Trim(f.Tag.Value, "`"))
-				if raw, ok := tag.Lookup("default"); ok {
-					hasDefault = true
-					defaultRaw = raw
-				}
+			tags := parseFieldTags(f.Tag)
+			if tags.skip {
+				printline(debug, "skipping field", fullname)
+				continue
+			}
+			if tags.env != "" && len(f.Names) > 1 {
+				panic("env tag on field " + n.Name + " would be shared by all names declared with it")
 			}
 
 			// we have encountered a struct defined in the same file
 			if childDefinition, ok := allTopLevelStructDefinitions[typ]; ok {
-				if hasDefault {
-					panic("default tag on struct-typed field " + n.Name + " is not supported")
+				if tag := tags.leafOnlyTag(); tag != "" {
+					panic(tag + " tag on struct-typed field " + n.Name + " is not supported")
+				}
+				envSegment := n.Name
+				if tags.hasEnvPrefix {
+					envSegment = tags.envPrefix
 				}
 				*parentNames = append(*parentNames, n.Name)
-				insertTemplateDataEntryForStruct(childDefinition, typ, parentNames, projectPrefix, outputImports, templateData, allTopLevelStructDefinitions, debug)
+				// an empty envprefix flattens the section into its parent
+				if envSegment != "" {
+					*envParentNames = append(*envParentNames, envSegment)
+				}
+				insertTemplateDataEntryForStruct(fset, childDefinition, typ, parentNames, envParentNames, projectPrefix, naming, caseInsensitive, outputImports, templateData, allTopLevelStructDefinitions, debug)
+				if envSegment != "" {
+					*envParentNames = (*envParentNames)[:len(*envParentNames)-1]
+				}
 				*parentNames = (*parentNames)[:len(*parentNames)-1]
 			} else {
-				canonicalNameList := append([]string{projectPrefix}, *parentNames...)
+				if tags.hasEnvPrefix {
+					panic("envprefix tag is only supported on struct-typed fields, got " + typ + " for field " + n.Name)
+				}
+				canonicalNameList := append([]string{projectPrefix}, *envParentNames...)
 				canonicalNameList = append(canonicalNameList, n.Name)
-				envKey := getEnvKey(canonicalNameList)
+				envKey := getEnvKey(canonicalNameList, naming)
+				errKey := getErrKey(canonicalNameList, naming.strategy)
+				if tags.env != "" {
+					envKey = tags.env
+					errKey = getErrKey(strings.Split(tags.env, "_"), naming.strategy)
+				}
+				errKey = naming.errName(errKey)
 
 				parseFunc, canHaveFormatErr, bitSize, castFunc, ok := lookupParseFunc(typ)
 				if !ok {
 					panic("unsupported type in config: " + typ)
 				}
-				errKey := getErrKey(canonicalNameList)
+				if tags.durationStyle != "" {
+					if typ != "time.Duration" {
+						panic("durationstyle tag is only supported on time.Duration fields, got " + typ + " for field " + n.Name)
+					}
+					parseFunc = lookupDurationParseFunc(tags.durationStyle)
+				}
 				missingErrVar := ""
-				if !hasDefault {
+				// a conditionally required field is checked once all fields are loaded
+				optional := tags.optional || tags.requiredIf != "" || len(tags.requiredWith) > 0
+				if !tags.hasDefault && !optional {
 					missingErrVar = errKey + "Missing"
 				}
 				invalidErrVar := ""
 				if canHaveFormatErr {
 					invalidErrVar = errKey + "Invalid"
 				}
+				conflictErrVar := ""
+				if len(tags.aliases) > 0 {
+					conflictErrVar = errKey + "Conflict"
+				}
+				ambiguousErrVar := ""
+				if caseInsensitive {
+					ambiguousErrVar = errKey + "Ambiguous"
+				}
 
 				if p := pkgForParseFunc(parseFunc); p != "" {
 					outputImports[p] = struct{}{}
 				}
+				if len(tags.normalize) > 0 {
+					// every normalize step is a strings function
+					outputImports[`"strings"`] = struct{}{}
+				}
+
+				patternVar := ""
+				if tags.pattern != "" {
+					name := strings.TrimPrefix(assignmentName, "val_")
+					if naming.scope != "" {
+						name = naming.scope + "_" + name
+					}
+					patternVar = "pattern_" + name
+				}
+				validations := buildValidations(fullname, typ, bitSize, parseFunc, patternVar, tags, outputImports)
 
 				*templateData = append(*templateData, TemplateData{
-					Name:           fullname,
-					AssignmentName: assignmentName,
-					EnvVar:         envKey,
-					ParseFunc:      parseFunc,
-					HasDefault:     hasDefault,
-					DefaultRaw:     defaultRaw,
-					MissingErrVar:  missingErrVar,
-					InvalidErrVar:  invalidErrVar,
-					FormatErr:      canHaveFormatErr,
-					BitSize:        bitSize,
-					CastFunc:       castFunc,
+					Name:            fullname,
+					AssignmentName:  assignmentName,
+					EnvVar:          envKey,
+					EnvConst:        naming.constName(envKey),
+					Position:        fset.Position(n.Pos()),
+					Aliases:         tags.aliases,
+					ConflictErrVar:  conflictErrVar,
+					AmbiguousErrVar: ambiguousErrVar,
+					ParseFunc:       parseFunc,
+					HasDefault:      tags.hasDefault,
+					DefaultRaw:      tags.defaultRaw,
+					MissingErrVar:   missingErrVar,
+					InvalidErrVar:   invalidErrVar,
+					FormatErr:       canHaveFormatErr,
+					BitSize:         bitSize,
+					CastFunc:        castFunc,
+					Normalize:       tags.normalize,
+					TrimsSpace:      slices.Contains(tags.normalize, "strings.TrimSpace"),
+					ProfileDefaults: tags.profiles,
+					Validations:     validations,
+					Pattern:         tags.pattern,
+					PatternVar:      patternVar,
+					EmptyIsUnset:    tags.nonEmpty,
+					Optional:        optional,
+					GoType:          typ,
+					RequiredIf:      tags.requiredIf,
+					RequiredWith:    tags.requiredWith,
+					Excludes:        tags.excludes,
+					FlagName:        strings.ToLower(fullname),
+					Doc:             fieldDoc(f),
+					TypeHint:        typeHint(typ, parseFunc),
 				})
 			}
 
@@ -246,14 +597,28 @@ func insertTemplateDataEntryForStruct(structDefinition *ast.StructType, structNa
 	}
 }
 
-func getEnvKey(canonicalNameList []string) string {
+// fieldDoc returns the doc comment of a field, or its trailing comment if it
+// has none, joined into a single line.
+func fieldDoc(f *ast.Field) string {
+	doc := f.Doc
+	if doc == nil {
+		doc = f.Comment
+	}
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+func getEnvKey(canonicalNameList []string, naming envNaming) string {
+	if naming.strategy == NamingSnake || naming.strategy == NamingKebab {
+		return getWordSplitEnvKey(canonicalNameList, naming)
+	}
 	sb := &strings.Builder{}
 	for _, part := range canonicalNameList {
 		for _, r := range part {
 			// keep only letters and digits in the env var name. This is prone
 			// to errors e.g. if someone names a field "my_field" and has a field
-			// called "my" of type struct that has a field called "field" but
-			// come on
+			// called "my" of type struct that has a field called "field", which
+			// is why detectCollisions runs before anything is written. A
+			// separator such as "__" makes the boundary unambiguous.
 			if unicode.IsDigit(r) {
 				sb.WriteRune(r)
 			}
@@ -261,12 +626,15 @@ func getEnvKey(canonicalNameList []string) string {
 				sb.WriteRune(unicode.ToUpper(r))
 			}
 		}
-		sb.WriteRune('_')
+		sb.WriteString(naming.separator)
 	}
-	return strings.TrimSuffix(sb.String(), "_")
+	return strings.TrimSuffix(sb.String(), naming.separator)
 }
 
-func getErrKey(canonicalNameList []string) string {
+func getErrKey(canonicalNameList []string, naming string) string {
+	if naming == NamingSnake || naming == NamingKebab {
+		return getWordSplitErrKey(canonicalNameList)
+	}
 	sb := &strings.Builder{}
 	sb.WriteString("Err")
 	for _, part := range canonicalNameList {
@@ -291,13 +659,31 @@ func getErrKey(canonicalNameList []string) string {
 
 func setupImportsAlwaysNeeded() map[string]struct{} {
 	return map[string]struct{}{
-		`"os"`:      {},
-		`"strings"`: {},
-		`"errors"`:  {},
+		`"os"`:     {},
+		`"errors"`: {},
 	}
 
 }
 
+// registerRuntimeImports adds the packages used by the runtime
+// declarations of data, the ones shared by all configs of a package. Their
+// helpers register their own imports.
+func registerRuntimeImports(data loaderData, outputImports map[string]struct{}) {
+	outputImports[`"strings"`] = struct{}{}
+	if data.Flags {
+		outputImports[`"flag"`] = struct{}{}
+	}
+	if data.Secrets {
+		for _, p := range []string{`"context"`, `"path/filepath"`, `"time"`} {
+			outputImports[p] = struct{}{}
+		}
+	}
+	if data.ErrorReport {
+		outputImports[`"strconv"`] = struct{}{}
+		outputImports[`"text/tabwriter"`] = struct{}{}
+	}
+}
+
 func generateImportsListAsTemplateString(outputImports map[string]struct{}) string {
 	pkgs := make([]string, 0, len(outputImports))
 	for p := range outputImports {
@@ -385,6 +771,61 @@ func lookupParseFunc(typ string) (parseFunc string, canHaveFormatErr bool, bitSi
 	}
 }
 
+// lookupDurationParseFunc maps a durationstyle:"..." tag value to the
+// function used to parse the field.
+func lookupDurationParseFunc(style string) string {
+	switch style {
+	case "go":
+		return "time.ParseDuration"
+	case "extended":
+		return "parseExtendedDuration"
+	case "iso8601":
+		return "parseISO8601Duration"
+	default:
+		panic("unsupported durationstyle: " + style)
+	}
+}
+
+// typeHint describes the values a field accepts, with an example when the
+// type alone does not tell how to write one.
+func typeHint(typ string, parseFunc string) string {
+	switch {
+	case typ == "bool":
+		return "bool (true or false)"
+	case strings.HasPrefix(typ, "int"), strings.HasPrefix(typ, "uint"):
+		return typ + " (e.g. 42)"
+	case strings.HasPrefix(typ, "float"):
+		return typ + " (e.g. 0.5)"
+	case parseFunc == "parseExtendedDuration":
+		return "duration (e.g. 30s or 1w2d)"
+	case parseFunc == "parseISO8601Duration":
+		return "duration (e.g. PT30S or P7D)"
+	case typ == "time.Duration":
+		return "duration (e.g. 30s or 1h30m)"
+	default:
+		return typ
+	}
+}
+
+// lookupNormalizeFuncs maps the comma separated steps of a normalize:"..."
+// tag to the functions applied to the raw value, keeping their order.
+func lookupNormalizeFuncs(raw string) []string {
+	var funcs []string
+	for _, step := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(step) {
+		case "trim":
+			funcs = append(funcs, "strings.TrimSpace")
+		case "lower":
+			funcs = append(funcs, "strings.ToLower")
+		case "upper":
+			funcs = append(funcs, "strings.ToUpper")
+		default:
+			panic("unsupported normalize step: " + step)
+		}
+	}
+	return funcs
+}
+
 func pkgForParseFunc(fn string) string {
 	switch {
 	case strings.HasPrefix(fn, "strconv."):
@@ -396,89 +837,388 @@ func pkgForParseFunc(fn string) string {
 	}
 }
 
-var goTemplate = template.Must(template.New("config").Parse(`// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
-
-{{- if .TestBuildTag }}
-//go:build {{ .TestBuildTag }}
-// +build {{ .TestBuildTag }}
-
-{{- end }}
-
-package {{ .PackageName }}
-
-import (
-	{{ .ImportList }}
-)
+var goTemplate = template.Must(template.New("config").Parse(`{{ template "header" . }}
 
 const (
 {{- range .Fields }}
-	{{ .EnvVar }}_ENV = "{{ .EnvVar }}"
+	{{ .EnvConst }} = "{{ .EnvVar }}"
+{{- end }}
+{{- if .Profiles }}
+	{{ .ProfileEnvConst }} = "{{ .ProfileEnvVar }}"
 {{- end }}
 )
 
 var (
 {{- range .Fields }}
 {{- if .MissingErrVar }}
-	{{ .MissingErrVar }} = errors.New({{ .EnvVar }}_ENV)
+	{{ .MissingErrVar }} = errors.New({{ .EnvConst }})
 {{- end }}
 {{- if .InvalidErrVar }}
-	{{ .InvalidErrVar }} = errors.New({{ .EnvVar }}_ENV)
+	{{ .InvalidErrVar }} = errors.New({{ .EnvConst }})
+{{- end }}
+{{- if .ConflictErrVar }}
+	{{ .ConflictErrVar }} = errors.New({{ .EnvConst }})
+{{- end }}
+{{- if .AmbiguousErrVar }}
+	{{ .AmbiguousErrVar }} = errors.New({{ .EnvConst }})
+{{- end }}
+{{- if .PatternVar }}
+	{{ .PatternVar }} = regexp.MustCompile({{ printf "%q" .Pattern }})
 {{- end }}
 {{- end }}
 )
+{{- if .ErrorReport }}
+
+func init() {
+{{- range .Fields }}
+	fieldInfos[{{ .EnvConst }}] = fieldInfo{path: {{ printf "%q" .Name }}, typ: {{ printf "%q" .TypeHint }}{{ if .HasDefault }}, def: {{ printf "%q" .DefaultRaw }}, hasDefault: true{{ end }}{{ if .Doc }}, doc: {{ printf "%q" .Doc }}{{ end }}}
+{{- end }}
+}
+{{- end }}
+
+// Load{{ .StructName }} reads the config from the environment.
+func Load{{ .StructName }}(opts ...LoadOption) ({{ .StructName }}, error) {
+{{- if .CaseInsensitive }}
+	opts = append([]LoadOption{withEnvIndex(newEnvIndex(os.Environ()))}, opts...)
+{{- end }}
+	return Load{{ .StructName }}From(os.LookupEnv, opts...)
+}
+
+{{- if .JSONFile }}
+
+// Load{{ .StructName }}WithFile reads the config from the environment, falling
+// back to the JSON config file at path and then to the defaults.
+func Load{{ .StructName }}WithFile(path string, opts ...LoadOption) ({{ .StructName }}, error) {
+	return Load{{ .StructName }}(append(opts, WithConfigFile(path))...)
+}
+{{- end }}
+{{- if .Flags }}
+
+// Load{{ .StructName }}WithFlags reads the config from the flags set on fs,
+// falling back to the environment and then to the defaults. The flags must
+// have been registered with Register{{ .StructName }}Flags and fs must be parsed.
+func Load{{ .StructName }}WithFlags(fs *flag.FlagSet, opts ...LoadOption) ({{ .StructName }}, error) {
+	return Load{{ .StructName }}(append(opts, WithFlags(fs))...)
+}
+
+// Register{{ .StructName }}Flags defines a flag on fs for every field of
+// {{ .StructName }}, named after the lowercased field path, e.g. -server.port.
+// Values are parsed when the config is loaded, like env vars.
+func Register{{ .StructName }}Flags(fs *flag.FlagSet) {
+{{- range .Fields }}
+	fs.Var(&flagValue{envVar: {{ .EnvConst }}{{ if .HasDefault }}, value: {{ printf "%q" .DefaultRaw }}{{ end }}{{ if eq .ParseFunc "strconv.ParseBool" }}, isBool: true{{ end }}}, {{ printf "%q" .FlagName }}, {{ if .Doc }}{{ printf "%q" (printf "%s (env %s)" .Doc .EnvVar) }}{{ else }}{{ printf "%q" (printf "env %s" .EnvVar) }}{{ end }})
+{{- end }}
+}
+{{- end }}
+
+// Load{{ .StructName }}FromMap reads the config from m instead of the
+// environment.
+func Load{{ .StructName }}FromMap(m map[string]string, opts ...LoadOption) ({{ .StructName }}, error) {
+{{- if .CaseInsensitive }}
+	opts = append([]LoadOption{withEnvIndex(newEnvIndexFromMap(m))}, opts...)
+{{- end }}
+	return Load{{ .StructName }}From(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// Load{{ .StructName }}From reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+{{- if .CaseInsensitive }}
+//
+// Since lookup cannot be enumerated, names are passed to it as they are and
+// it is responsible for matching them regardless of case.
+{{- end }}
+func Load{{ .StructName }}From(lookup func(string) (string, bool), opts ...LoadOption) ({{ .StructName }}, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+{{- if and .SharedRuntime (not .Dotenv) }}
+	if len(options.dotenvFiles) > 0 {
+		return {{ .StructName }}{}, errors.New("Load{{ .StructName }}From is generated without dotenv support, WithDotenv cannot be used")
+	}
+{{- end }}
+{{- if and .SharedRuntime (not .JSONFile) }}
+	if options.configFile != "" {
+		return {{ .StructName }}{}, errors.New("Load{{ .StructName }}From is generated without JSON file support, WithConfigFile cannot be used")
+	}
+{{- end }}
+{{- if and .SharedRuntime (not .ConfigDir) }}
+	if options.configDir != "" {
+		return {{ .StructName }}{}, errors.New("Load{{ .StructName }}From is generated without config dir support, WithConfigDir cannot be used")
+	}
+{{- end }}
+{{- if and .SharedRuntime (not .Flags) }}
+	if options.flagSet != nil {
+		return {{ .StructName }}{}, errors.New("Load{{ .StructName }}From is generated without flag support, WithFlags cannot be used")
+	}
+{{- end }}
+{{- if and .SharedRuntime (not .Secrets) }}
+	if options.secretResolver != nil || options.secretTimeout != 0 {
+		return {{ .StructName }}{}, errors.New("Load{{ .StructName }}From is generated without secret support, WithSecretResolver and WithSecretTimeout cannot be used")
+	}
+{{- end }}
+{{- if and .SharedRuntime (not .HasProfiles) }}
+	if options.profile != "" {
+		return {{ .StructName }}{}, errors.New("Load{{ .StructName }}From is generated without profiles, WithProfile cannot be used")
+	}
+{{- end }}
+{{- if .CaseInsensitive }}
+	if options.envIndex != nil {
+		lookup = options.envIndex.lookup
+	}
+{{- end }}
+{{- if or .JSONFile .Dotenv .ConfigDir .Flags }}
+	sources := []func(string) (string, bool){lookup}
+{{- end }}
+{{- if .ConfigDir }}
+	if options.configDir != "" {
+		values, err := readConfigDir(options.configDir, []string{
+{{- range .Fields }}
+			{{ .EnvConst }},{{ range .Aliases }} {{ printf "%q" . }},{{ end }}
+{{- end }}
+		})
+		if err != nil {
+			return {{ .StructName }}{}, err
+		}
+		sources = append(sources, {{ template "valuelookup" . }})
+	}
+{{- end }}
+{{- if .Dotenv }}
+	if len(options.dotenvFiles) > 0 {
+		values, err := readDotenvFiles(options.dotenvFiles, {{ .Interpolation }})
+		if err != nil {
+			return {{ .StructName }}{}, err
+		}
+		sources = append(sources, {{ template "valuelookup" . }})
+	}
+{{- end }}
+{{- if .JSONFile }}
+	if options.configFile != "" {
+		values, err := readConfigFile(options.configFile, map[string]string{
+{{- range .Fields }}
+			{{ printf "%q" .Name }}: {{ .EnvConst }},
+{{- end }}
+		})
+		if err != nil {
+			return {{ .StructName }}{}, err
+		}
+		sources = append(sources, {{ template "valuelookup" . }})
+	}
+{{- end }}
+{{- if .Flags }}
+
+	if options.flagSet != nil {
+		flagValues := map[string]string{}
+		options.flagSet.Visit(func(f *flag.Flag) {
+			if v, ok := f.Value.(*flagValue); ok {
+				flagValues[v.envVar] = v.value
+			}
+		})
+		sources = append([]func(string) (string, bool){func(name string) (string, bool) {
+			value, ok := flagValues[name]
+			return value, ok
+		}}, sources...)
+	}
+{{- end }}
+{{- if or .JSONFile .Dotenv .ConfigDir .Flags }}
+	lookup = layerLookups({{ if .HasAliases }}[][]string{
+{{- range .Fields }}
+{{- if .Aliases }}
+		{ {{- .EnvConst }}{{ range .Aliases }}, {{ printf "%q" . }}{{ end -}} },
+{{- end }}
+{{- end }}
+	}{{ else }}nil{{ end }}, sources...)
+{{- end }}
+{{- if .Secrets }}
+
+	resolver := options.secretResolver
+	if resolver == nil {
+		resolver = FileSecretResolver{}
+	}
+	timeout := options.secretTimeout
+	if timeout <= 0 {
+		timeout = defaultSecretTimeout
+	}
+{{- end }}
+{{- if or .Profiles .Interpolation }}
+	defaults := map[string]string{
+{{- range .Fields }}
+{{- if .HasDefault }}
+		{{ .EnvConst }}: {{ printf "%q" .DefaultRaw }},
+{{- end }}
+{{- end }}
+	}
+{{- end }}
+{{- if .Profiles }}
+	profile := options.profile
+	if profile == "" {
+		profile, _ = lookup({{ .ProfileEnvConst }})
+	}
+	switch profile {
+	case "":
+{{- range .Profiles }}
+	case {{ printf "%q" .Name }}:
+{{- range .Defaults }}
+		defaults[{{ .EnvConst }}] = {{ printf "%q" .Raw }}
+{{- end }}
+{{- end }}
+	default:
+		return {{ .StructName }}{}, UnknownProfileError{Profile: profile, Known: []string{ {{- range $i, $p := .Profiles }}{{ if $i }}, {{ end }}{{ printf "%q" $p.Name }}{{ end -}} }}
+	}
+{{- end }}
+{{- if .Interpolation }}
+	exp := newExpander(lookup, defaults)
+	lookup = exp.lookupExpanded
+{{- end }}
+{{- if .Secrets }}
+	values, {{ if .HasFormatErrs }}secrets{{ else }}_{{ end }}, err := resolveSecrets(resolver, timeout, lookup, []string{
+{{- range .Fields }}
+		{{ .EnvConst }},{{ range .Aliases }} {{ printf "%q" . }},{{ end }}
+{{- end }}
+	})
+	if err != nil {
+		return {{ .StructName }}{}, err
+	}
+	lookup = func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+{{- end }}
 
-func Load{{ .StructName }}() ({{ .StructName }}, error) {
 	var config {{ .StructName }}
 	var missingVars []error
 	var formatVars []error
+{{- if .HasValidations }}
+	var violations []error
+{{- end }}
+{{- if .HasAliases }}
+	var conflictVars []error
+{{- end }}
+{{- if .CaseInsensitive }}
+	var ambiguousVars []error
+{{- end }}
 
 {{- range .Fields }}
-	{{ .AssignmentName }}, ok := os.LookupEnv({{ .EnvVar }}_ENV)
+{{- if .AmbiguousErrVar }}
+	if options.envIndex.ambiguous({{ .EnvConst }}{{ range .Aliases }}, {{ printf "%q" . }}{{ end }}) {
+		ambiguousVars = append(ambiguousVars, {{ .AmbiguousErrVar }})
+	}
+{{- end }}
+{{- if .Aliases }}
+	{{ .AssignmentName }}, from, ok, conflict := lookupEnvWithAliases(lookup, {{ if .EmptyIsUnset }}func(value string) bool { return {{ if .TrimsSpace }}strings.TrimSpace(value){{ else }}value{{ end }} == "" }{{ else }}nil{{ end }}, {{ .EnvConst }}{{ range .Aliases }}, {{ printf "%q" . }}{{ end }})
+	if conflict {
+		conflictVars = append(conflictVars, {{ .ConflictErrVar }})
+	} else if ok && from != {{ .EnvConst }} {
+		options.warn(Warning{EnvVar: {{ .EnvConst }}, Alias: from})
+	}
+{{- else }}
+	{{ .AssignmentName }}, ok := lookup({{ .EnvConst }})
+{{- if .EmptyIsUnset }}
+	if {{ if .TrimsSpace }}strings.TrimSpace({{ .AssignmentName }}){{ else }}{{ .AssignmentName }}{{ end }} == "" {
+		ok = false
+	}
+{{- end }}
+{{- end }}
+{{- if .PresenceVar }}
+	{{ .PresenceVar }} := ok
+{{- end }}
+{{- if and .Optional (not .ProfileDefaults) }}
+	if ok {
+{{- else }}
 	if !ok {
-{{- if .HasDefault }}
-		{{ .AssignmentName }} = {{ printf "%q" .DefaultRaw }}
+{{- end }}
+{{- if .ProfileDefaults }}
+{{- if $.Interpolation }}
+		_, ok = defaults[{{ .EnvConst }}]
+		if ok {
+			{{ .AssignmentName }} = exp.expandDefault({{ .EnvConst }})
+		}
+{{- else }}
+		{{ .AssignmentName }}, ok = defaults[{{ .EnvConst }}]
+{{- end }}
+{{- if .MissingErrVar }}
+		if !ok {
+			{{- template "missing" . }}
+		}
+{{- end }}
+	}
+	if ok {
+{{- else if .HasDefault }}
+		{{ .AssignmentName }} = {{ if .DefaultExpands }}exp.expandDefault({{ .EnvConst }}){{ else }}{{ printf "%q" .DefaultRaw }}{{ end }}
 		ok = true
 	}
 	if ok {
+{{- else if .Optional }}
 {{- else }}
-		missingVars = append(missingVars, {{ .MissingErrVar }})
+		{{- template "missing" . }}
 	} else {
 {{- end }}
+		{{- $assignmentName := .AssignmentName }}
+		{{- range .Normalize }}
+		{{ $assignmentName }} = {{ . }}({{ $assignmentName }})
+		{{- end }}
 		{{- if eq .ParseFunc "raw" }}
 		config.{{ .Name }} = {{ .AssignmentName }}
+		{{- template "validations" . }}
 		{{- else if eq .ParseFunc "strconv.Atoi" }}
 		parsed, err := strconv.Atoi({{ .AssignmentName }})
 		if err != nil {
-			formatVars = append(formatVars, {{ .InvalidErrVar }})
+			{{- template "invalid" . }}
 		} else {
 			config.{{ .Name }} = {{ if .CastFunc }}{{ .CastFunc }}(parsed){{ else }}parsed{{ end }}
+			{{- template "validations" . }}
 		}
 		{{- else if or (eq .ParseFunc "strconv.ParseInt") (eq .ParseFunc "strconv.ParseUint") }}
 		parsed, err := {{ .ParseFunc }}({{ .AssignmentName }}, 10, {{ .BitSize }})
 		if err != nil {
-			formatVars = append(formatVars, {{ .InvalidErrVar }})
+			{{- template "invalid" . }}
 		} else {
 			config.{{ .Name }} = {{ if .CastFunc }}{{ .CastFunc }}(parsed){{ else }}parsed{{ end }}
+			{{- template "validations" . }}
 		}
 		{{- else if eq .ParseFunc "strconv.ParseFloat" }}
 		parsed, err := strconv.ParseFloat({{ .AssignmentName }}, {{ .BitSize }})
 		if err != nil {
-			formatVars = append(formatVars, {{ .InvalidErrVar }})
+			{{- template "invalid" . }}
 		} else {
 			config.{{ .Name }} = {{ if .CastFunc }}{{ .CastFunc }}(parsed){{ else }}parsed{{ end }}
+			{{- template "validations" . }}
 		}
 		{{- else }}
 		parsed, err := {{ .ParseFunc }}({{ .AssignmentName }})
 		if err != nil {
-			formatVars = append(formatVars, {{ .InvalidErrVar }})
+			{{- template "invalid" . }}
 		} else {
 			config.{{ .Name }} = parsed
+			{{- template "validations" . }}
 		}
 		{{- end }}
 	}
 {{- end }}
+{{- if .Conditions }}
+{{ range .Conditions }}
+	if {{ .Cond }} {
+		violations = append(violations, ConditionViolation{Rule: {{ printf "%q" .Rule }}, Field: {{ printf "%q" .Field }}, EnvVar: {{ .EnvConst }}, OtherField: {{ printf "%q" .OtherField }}, OtherEnvVar: {{ .OtherConst }}{{ if .OtherValue }}, OtherValue: {{ printf "%q" .OtherValue }}{{ end }}})
+	}
+{{- end }}
+{{- end }}
+{{- if .ValidateCalls }}
+
+	// sections are only validated once all of their fields are loaded
+	var sectionErrs []error
+	if len(missingVars) == 0 && len(formatVars) == 0{{ if .Interpolation }} && len(exp.cycles) == 0{{ end }}{{ if .HasAliases }} && len(conflictVars) == 0{{ end }}{{ if .CaseInsensitive }} && len(ambiguousVars) == 0{{ end }} {
+{{- range .ValidateCalls }}
+		if err := config{{ if .Path }}.{{ .Path }}{{ end }}.Validate(); err != nil {
+			sectionErrs = append(sectionErrs, SectionError{Config: {{ printf "%q" $.StructName }}, Section: {{ printf "%q" .Path }}, Err: err})
+		}
+{{- end }}
+	}
+{{- end }}
 
-	if len(missingVars) > 0 || len(formatVars) > 0 {
+	if len(missingVars) > 0 || len(formatVars) > 0{{ if .Interpolation }} || len(exp.cycles) > 0{{ end }}{{ if .HasValidations }} || len(violations) > 0{{ end }}{{ if .ValidateCalls }} || len(sectionErrs) > 0{{ end }}{{ if .HasAliases }} || len(conflictVars) > 0{{ end }}{{ if .CaseInsensitive }} || len(ambiguousVars) > 0{{ end }} {
 		var verr error
 		if len(missingVars) > 0 {
 			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
@@ -486,11 +1226,402 @@ func Load{{ .StructName }}() ({{ .StructName }}, error) {
 		if len(formatVars) > 0 {
 			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
 		}
+{{- if .Interpolation }}
+		if len(exp.cycles) > 0 {
+			verr = errors.Join(verr, CyclicEnvVarsError{vars: exp.cycles})
+		}
+{{- end }}
+{{- if .HasValidations }}
+		if len(violations) > 0 {
+			verr = errors.Join(verr, ValidationError{vars: violations})
+		}
+{{- end }}
+{{- if .ValidateCalls }}
+		verr = errors.Join(append([]error{verr}, sectionErrs...)...)
+{{- end }}
+{{- if .HasAliases }}
+		if len(conflictVars) > 0 {
+			verr = errors.Join(verr, ConflictingEnvVarsError{vars: conflictVars})
+		}
+{{- end }}
+{{- if .CaseInsensitive }}
+		if len(ambiguousVars) > 0 {
+			verr = errors.Join(verr, AmbiguousEnvVarsError{vars: ambiguousVars})
+		}
+{{- end }}
 		return {{ .StructName }}{}, verr
 	}
 
 	return config, nil
 }
+{{- if not .SharedRuntime }}
+
+{{ template "runtime" . }}
+{{- end }}
+
+{{- define "runtime" -}}
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning   func(Warning)
+{{- if .Dotenv }}
+	dotenvFiles []string
+{{- end }}
+{{- if .JSONFile }}
+	configFile  string
+{{- end }}
+{{- if .ConfigDir }}
+	configDir   string
+{{- end }}
+{{- if .Flags }}
+	flagSet     *flag.FlagSet
+{{- end }}
+{{- if .Secrets }}
+
+	secretResolver SecretResolver
+	secretTimeout  time.Duration
+{{- end }}
+{{- if .HasProfiles }}
+	profile        string
+{{- end }}
+{{- if .CaseInsensitive }}
+	envIndex    envIndex
+{{- end }}
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+{{- if .Dotenv }}
+
+// WithDotenv layers the values of dotenv files under the real environment.
+// Later files take precedence over earlier ones, and files that do not
+// exist are skipped, e.g. WithDotenv(".env", ".env.local").
+func WithDotenv(paths ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenvFiles = append(o.dotenvFiles, paths...)
+	}
+}
+{{- end }}
+
+{{- if .JSONFile }}
+
+// WithConfigFile layers the values of a JSON config file under the real
+// environment and any dotenv files. The document mirrors the nesting of
+// the config struct, keyed by field name, e.g. {"Server": {"Port": 8080}}.
+func WithConfigFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.configFile = path
+	}
+}
+{{- end }}
+
+{{- if .ConfigDir }}
+
+// WithConfigDir layers the files of a directory, such as a mounted
+// ConfigMap or Secret, under the real environment. Each file is named after
+// an env var, in its exact, lowercased or dotted form (e.g. app.db.url),
+// and takes precedence over dotenv and JSON config files. The directory is
+// read on every load, so updates to the mount are picked up.
+func WithConfigDir(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.configDir = path
+	}
+}
+{{- end }}
+
+{{- if .CaseInsensitive }}
+
+func withEnvIndex(idx envIndex) LoadOption {
+	return func(o *loadOptions) {
+		o.envIndex = idx
+	}
+}
+{{- end }}
+
+{{- if .Flags }}
+
+// WithFlags gives the flags set on fs precedence over every other source.
+// fs must be parsed and its flags registered with the Register...Flags
+// function of the config.
+func WithFlags(fs *flag.FlagSet) LoadOption {
+	return func(o *loadOptions) {
+		o.flagSet = fs
+	}
+}
+{{- end }}
+{{- if .Secrets }}
+
+// WithSecretResolver sets the resolver used for values that are secret
+// references, e.g. secretref://payments/db-password. It defaults to
+// FileSecretResolver{}.
+func WithSecretResolver(r SecretResolver) LoadOption {
+	return func(o *loadOptions) {
+		o.secretResolver = r
+	}
+}
+
+// WithSecretTimeout bounds the time spent resolving all secret references,
+// which are resolved concurrently. It defaults to 10 seconds.
+func WithSecretTimeout(d time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.secretTimeout = d
+	}
+}
+
+const (
+	secretRefScheme      = "secretref://"
+	defaultSecretTimeout = 10 * time.Second
+)
+
+// SecretResolver resolves a secret reference, such as
+// secretref://payments/db-password, into the secret it points to.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, ref string) (string, error)
+}
+
+// FileSecretResolver resolves secretref://path by reading the file at path,
+// relative to Dir if it is set, and trimming trailing newlines. With Dir
+// set, paths that escape it are rejected.
+type FileSecretResolver struct {
+	Dir string
+}
+
+func (r FileSecretResolver) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	path, ok := strings.CutPrefix(ref, secretRefScheme)
+	if !ok || path == "" {
+		return "", errors.New("invalid secret reference " + ref)
+	}
+	if r.Dir != "" {
+		if !filepath.IsLocal(path) {
+			return "", errors.New("secret reference " + ref + " is outside of " + r.Dir)
+		}
+		path = filepath.Join(r.Dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+{{- end }}
+{{- if .HasProfiles }}
+
+// WithProfile selects the profile whose defaults apply, taking precedence
+// over the <PREFIX>_PROFILE env var.
+func WithProfile(name string) LoadOption {
+	return func(o *loadOptions) {
+		o.profile = name
+	}
+}
+
+// UnknownProfileError is returned when the selected profile has no defaults
+// defined for it.
+type UnknownProfileError struct {
+	Profile string
+	Known   []string
+}
+
+func (e UnknownProfileError) Error() string {
+	return "unknown profile \"" + e.Profile + "\", expected one of " + strings.Join(e.Known, ", ")
+}
+{{- end }}
+{{- if .Flags }}
+
+// flagValue holds the raw value of a flag, which is parsed together with
+// the other sources of envVar.
+type flagValue struct {
+	envVar string
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+{{- end }}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+{{- if .ErrorReport }}
+
+// fieldInfo describes a field of a config for FormatConfigError.
+type fieldInfo struct {
+	path       string
+	typ        string // type with an example value, e.g. duration (e.g. 30s)
+	def        string
+	hasDefault bool
+	doc        string
+}
+
+// fieldInfos holds the fields of every config of the package, keyed by env
+// var. Each config adds its fields when the package is initialized.
+var fieldInfos = map[string]fieldInfo{}
+
+// FormatConfigError formats an error returned by a Load function as a table
+// with one row per env var at fault, showing its field, type with an example
+// value, default, what went wrong and the doc comment of the field. Errors
+// that are not about a single env var, such as those of Validate methods,
+// are listed below the table.
+func FormatConfigError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var rows [][2]string // env var and problem
+	var others []string
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case FieldError:
+			if e.Kind == "missing" {
+				rows = append(rows, [2]string{e.EnvVar, "not set"})
+			} else {
+				rows = append(rows, [2]string{e.EnvVar, "invalid value " + strconv.Quote(e.Value) + ": " + e.reason})
+			}
+{{- if .HasValidations }}
+		case violation:
+			rows = append(rows, [2]string{e.envVar, "violates " + e.constraint})
+{{- end }}
+{{- if .HasConditions }}
+		case ConditionViolation:
+			problem := "excludes " + e.OtherEnvVar
+			switch e.Rule {
+			case "requiredif":
+				problem = "required when " + e.OtherEnvVar + " is " + e.OtherValue
+			case "requiredwith":
+				problem = "required with " + e.OtherEnvVar
+			}
+			rows = append(rows, [2]string{e.EnvVar, problem})
+{{- end }}
+{{- if .Interpolation }}
+		case cycleError:
+			rows = append(rows, [2]string{e.envVar, "references itself: " + e.chain})
+{{- end }}
+{{- if .Secrets }}
+		case secretError:
+			rows = append(rows, [2]string{e.envVar, "secret " + e.ref + " could not be resolved: " + e.err.Error()})
+{{- end }}
+{{- if .HasAliases }}
+		case ConflictingEnvVarsError:
+			for _, v := range e.vars {
+				rows = append(rows, [2]string{v.Error(), "set to different values through its aliases"})
+			}
+{{- end }}
+{{- if .CaseInsensitive }}
+		case AmbiguousEnvVarsError:
+			for _, v := range e.vars {
+				rows = append(rows, [2]string{v.Error(), "set more than once with different case"})
+			}
+{{- end }}
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		default:
+			others = append(others, err.Error())
+		}
+	}
+	walk(err)
+
+	var table strings.Builder
+	if len(rows) > 0 {
+		w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		w.Write([]byte("ENV VAR\tFIELD\tTYPE\tDEFAULT\tPROBLEM\tDESCRIPTION\n"))
+		for _, row := range rows {
+			info, ok := fieldInfos[row[0]]
+			if !ok {
+				info = fieldInfo{path: "-", typ: "-"}
+			}
+			def := "-"
+			if info.hasDefault {
+				def = strconv.Quote(info.def)
+			}
+			w.Write([]byte(strings.Join([]string{row[0], info.path, info.typ, def, row[1], info.doc}, "\t") + "\n"))
+		}
+		w.Flush()
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+		if line = strings.TrimRight(line, " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(append(lines, others...), "\n")
+}
+{{- end }}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
 
 type MissingEnvVarsError struct {
 	vars []error
@@ -501,14 +1632,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -520,13 +1644,205 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+{{- if .HasValidations }}
+// ValidationError lists the env vars whose values violate the constraints
+// of their fields, each with the constraint that failed.
+type ValidationError struct {
+	vars []error
+}
+
+func (m ValidationError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ValidationError) Error() string {
+	return joinEnvVars("envs ", m.vars, " violate their constraints")
+}
+
+{{ if .HasConditions -}}
+// ConditionViolation is a violated requiredif, requiredwith or excludes tag.
+// It names both fields and their env vars.
+type ConditionViolation struct {
+	Rule        string // requiredif, requiredwith or excludes
+	Field       string
+	EnvVar      string
+	OtherField  string
+	OtherEnvVar string
+	OtherValue  string // value of OtherField that makes Field required, for requiredif
+}
+
+func (v ConditionViolation) Error() string {
+	switch v.Rule {
+	case "requiredif":
+		return v.EnvVar + " (required when " + v.OtherEnvVar + " is " + v.OtherValue + ")"
+	case "requiredwith":
+		return v.EnvVar + " (required with " + v.OtherEnvVar + ")"
+	default:
+		return v.EnvVar + " (excludes " + v.OtherEnvVar + ")"
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+}
+
+{{ end -}}
+type violation struct {
+	envVar     string
+	constraint string
+}
+
+func (v violation) Error() string {
+	return v.envVar + " (" + v.constraint + ")"
+}
+
+{{ end -}}
+{{- if .HasValidateCalls }}
+// SectionError is an error returned by the Validate method of a section of
+// the config, labelled with the field path of the section.
+type SectionError struct {
+	Config  string // name of the config struct
+	Section string // empty for the config struct itself
+	Err     error
+}
+
+func (e SectionError) Error() string {
+	if e.Section == "" {
+		return e.Config + ": " + e.Err.Error()
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return e.Section + ": " + e.Err.Error()
+}
+
+func (e SectionError) Unwrap() error {
+	return e.Err
+}
+
+{{ end -}}
+{{ if .Interpolation -}}
+// CyclicEnvVarsError lists the env vars whose values or defaults reference
+// themselves through ${...}, together with the chain of references.
+type CyclicEnvVarsError struct {
+	vars []error
+}
+
+func (m CyclicEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m CyclicEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " reference themselves")
+}
+{{- end }}
+{{- if .Secrets }}
+
+// UnresolvedSecretsError lists the env vars whose secret references could
+// not be resolved. Each of them unwraps to the error of the resolver.
+type UnresolvedSecretsError struct {
+	vars []error
+}
+
+func (m UnresolvedSecretsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m UnresolvedSecretsError) Error() string {
+	return joinEnvVars("secrets of envs ", m.vars, " could not be resolved")
+}
+
+type secretError struct {
+	envVar string
+	ref    string
+	err    error
+}
+
+func (e secretError) Error() string {
+	return e.envVar + " (" + e.ref + ": " + e.err.Error() + ")"
+}
+
+func (e secretError) Unwrap() error {
+	return e.err
+}
+{{- end }}
+{{- if .HasAliases }}
+
+type ConflictingEnvVarsError struct {
+	vars []error
+}
+
+func (m ConflictingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ConflictingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are set to different values through their aliases")
+}
+{{- end }}
+{{- if .CaseInsensitive }}
+
+type AmbiguousEnvVarsError struct {
+	vars []error
 }
+
+func (m AmbiguousEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m AmbiguousEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are set more than once with different case")
+}
+{{- end }}
+{{- if .Helpers }}
+
+{{ .Helpers }}
+{{- end }}
+{{- end }}
+
+{{- define "header" -}}
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+
+{{- if .TestBuildTag }}
+//go:build {{ .TestBuildTag }}
+// +build {{ .TestBuildTag }}
+
+{{- end }}
+
+package {{ .PackageName }}
+
+import (
+	{{ .ImportList }}
+)
+{{- end }}
+
+{{- define "runtimefile" }}
+{{- template "header" . }}
+
+{{ template "runtime" . }}
+{{- end }}
+
+{{- define "valuelookup" -}}
+{{- if .CaseInsensitive -}}
+newEnvIndexFromMap(values).lookup
+{{- else -}}
+func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		}
+{{- end -}}
+{{- end }}
+
+{{- define "missing" }}
+		missingVars = append(missingVars, FieldError{Path: {{ printf "%q" .Name }}, EnvVar: {{ .EnvConst }}, Kind: "missing", sentinel: {{ .MissingErrVar }}})
+{{- end }}
+
+{{- define "invalid" }}
+			formatVars = append(formatVars, newFieldError({{ printf "%q" .Name }}, {{ .EnvConst }}, {{ .InvalidErrVar }}, {{ .AssignmentName }}, {{ if .SecretRefs }}secrets[{{ if .Aliases }}from{{ else }}{{ .EnvConst }}{{ end }}]{{ else }}false{{ end }}, err))
+{{- end }}
+
+{{- define "validations" }}
+{{- $envConst := .EnvConst }}
+{{- range .Validations }}
+		if {{ .Cond }} {
+			violations = append(violations, violation{envVar: {{ $envConst }}, constraint: {{ printf "%q" .Constraint }}})
+		}
+{{- end }}
+{{- end }}
 `))
@@ -0,0 +1,176 @@
+package genconfig
+
+import (
+	_ "embed"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The duration parsers are both called by the generator, to check duration
+// bounds, and emitted into generated loaders from the source of this file.
+
+//go:embed durations.go
+var durationsSource string
+
+// funcSource returns the source of the top level function name in src,
+// including its doc comment.
+func funcSource(src string, name string) string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		panic(err)
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != name {
+			continue
+		}
+		start := fn.Pos()
+		if fn.Doc != nil {
+			start = fn.Doc.Pos()
+		}
+		return src[fset.Position(start).Offset:fset.Position(fn.End()).Offset] + "\n"
+	}
+	panic("no function " + name + " in source")
+}
+
+// parseExtendedDuration accepts everything time.ParseDuration does, plus
+// the units "d" (24h) and "w" (7d), e.g. "1w2d12h".
+func parseExtendedDuration(s string) (time.Duration, error) {
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, errors.New("invalid duration " + strconv.Quote(orig))
+	}
+	var d time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && (s[i] == '.' || ('0' <= s[i] && s[i] <= '9')) {
+			i++
+		}
+		j := i
+		for j < len(s) && s[j] != '.' && (s[j] < '0' || s[j] > '9') {
+			j++
+		}
+		num, unit := s[:i], s[i:j]
+		s = s[j:]
+		if num == "" || unit == "" {
+			return 0, errors.New("invalid duration " + strconv.Quote(orig))
+		}
+		var part time.Duration
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig))
+			}
+			u := 24 * time.Hour
+			if unit == "w" {
+				u = 7 * 24 * time.Hour
+			}
+			v := math.Round(f * float64(u))
+			if v >= math.MaxInt64 {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig) + ": out of range")
+			}
+			part = time.Duration(v)
+		default:
+			p, err := time.ParseDuration(num + unit)
+			if err != nil {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig))
+			}
+			part = p
+		}
+		if d > math.MaxInt64-part {
+			return 0, errors.New("invalid duration " + strconv.Quote(orig) + ": out of range")
+		}
+		d += part
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseISO8601Duration parses ISO 8601 durations such as "P7D" or
+// "PT1H30M". Years and months have no fixed length and are rejected.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	invalid := errors.New("invalid ISO 8601 duration " + strconv.Quote(s))
+	rest := strings.ToUpper(s)
+	neg := false
+	if rest != "" && (rest[0] == '-' || rest[0] == '+') {
+		neg = rest[0] == '-'
+		rest = rest[1:]
+	}
+	if rest == "" || rest[0] != 'P' {
+		return 0, invalid
+	}
+	rest = rest[1:]
+	var d time.Duration
+	inTime, seen := false, false
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime || len(rest) == 1 {
+				return 0, invalid
+			}
+			inTime = true
+			rest = rest[1:]
+			continue
+		}
+		i := 0
+		for i < len(rest) && (rest[i] == '.' || rest[i] == ',' || ('0' <= rest[i] && rest[i] <= '9')) {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return 0, invalid
+		}
+		f, err := strconv.ParseFloat(strings.Replace(rest[:i], ",", ".", 1), 64)
+		if err != nil {
+			return 0, invalid
+		}
+		designator := rest[i]
+		rest = rest[i+1:]
+		var unit time.Duration
+		switch {
+		case !inTime && designator == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && designator == 'D':
+			unit = 24 * time.Hour
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, errors.New("invalid ISO 8601 duration " + strconv.Quote(s) + ": years and months have no fixed length")
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		default:
+			return 0, invalid
+		}
+		v := math.Round(f * float64(unit))
+		if v >= math.MaxInt64 || d > math.MaxInt64-time.Duration(v) {
+			return 0, errors.New("invalid ISO 8601 duration " + strconv.Quote(s) + ": out of range")
+		}
+		d += time.Duration(v)
+		seen = true
+	}
+	if !seen {
+		return 0, invalid
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,726 @@
+package genconfig
+
+import (
+	"slices"
+	"strings"
+)
+
+// generatedHelper is a function that is emitted into the generated loader
+// only when at least one field needs it, together with the imports it uses.
+type generatedHelper struct {
+	imports []string
+	source  string
+}
+
+var generatedHelpers = map[string]generatedHelper{
+	"readDotenvFiles": {
+		imports: []string{`"errors"`, `"os"`, `"strconv"`, `"strings"`},
+		source: `// readDotenvFiles reads and merges dotenv files, later files taking
+// precedence. Files that do not exist are skipped. escapeDollar is passed
+// on to parseDotenv.
+func readDotenvFiles(paths []string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := parseDotenv(path, string(data), escapeDollar)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// parseDotenv parses the contents of a dotenv file. It supports comments,
+// an optional export prefix, single quoted values (taken literally), double
+// quoted values (with \n, \r, \t, \", \\ and \$ escapes, possibly spanning
+// several lines) and unquoted values, which end at an inline " #" comment.
+// With escapeDollar, the literal dollars of \$ escapes and single quoted
+// values are written as $$, so that interpolation keeps them as they are.
+func parseDotenv(path string, data string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	line := 1
+	fail := func(msg string) error {
+		return errors.New(path + ":" + strconv.Itoa(line) + ": " + msg)
+	}
+	lineEnd := func(i int) int {
+		if end := strings.IndexByte(data[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(data)
+	}
+	i := 0
+	for i < len(data) {
+		switch data[i] {
+		case '\n':
+			line++
+			i++
+			continue
+		case ' ', '\t', '\r':
+			i++
+			continue
+		case '#':
+			i = lineEnd(i)
+			continue
+		}
+
+		end := lineEnd(i)
+		if rest, ok := strings.CutPrefix(data[i:end], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			i += len("export")
+		}
+		eq := strings.IndexByte(data[i:end], '=')
+		if eq < 0 {
+			return nil, fail("expected KEY=value")
+		}
+		key := strings.TrimSpace(data[i : i+eq])
+		if key == "" || strings.ContainsAny(key, " \t'\"#") {
+			return nil, fail("invalid key " + strconv.Quote(key))
+		}
+		i += eq + 1
+		for i < end && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+
+		if i < end && (data[i] == '\'' || data[i] == '"') {
+			quote := data[i]
+			i++
+			startLine := line
+			var sb strings.Builder
+			closed := false
+			for i < len(data) {
+				c := data[i]
+				if c == quote {
+					closed = true
+					i++
+					break
+				}
+				if quote == '"' && c == '\\' && i+1 < len(data) {
+					switch next := data[i+1]; next {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					case '$':
+						if escapeDollar {
+							sb.WriteByte('$')
+						}
+						sb.WriteByte('$')
+					case '"', '\\':
+						sb.WriteByte(next)
+					default:
+						sb.WriteByte(c)
+						sb.WriteByte(next)
+						if next == '\n' {
+							line++
+						}
+					}
+					i += 2
+					continue
+				}
+				if c == '\n' {
+					line++
+				}
+				if quote == '\'' && c == '$' && escapeDollar {
+					sb.WriteByte('$')
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				line = startLine
+				return nil, fail("unterminated quoted value for " + key)
+			}
+			end = lineEnd(i)
+			if rest := strings.TrimSpace(data[i:end]); rest != "" && rest[0] != '#' {
+				return nil, fail("unexpected characters after quoted value for " + key)
+			}
+			values[key] = sb.String()
+		} else {
+			raw := data[i:end]
+			if comment := strings.Index(raw, " #"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			if comment := strings.Index(raw, "\t#"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			values[key] = strings.TrimSpace(raw)
+		}
+		i = end
+	}
+	return values, nil
+}
+`,
+	},
+	"readConfigFile": {
+		imports: []string{`"encoding/json"`, `"errors"`, `"os"`, `"sort"`, `"strconv"`},
+		source: `// readConfigFile reads a JSON config file and returns its values keyed by
+// env var. paths maps the dotted path of every field to its env var; other
+// keys are rejected so that typos do not go unnoticed. Values are kept as
+// text and parsed like the environment, and null leaves a field unset.
+func readConfigFile(path string, paths map[string]string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	values := map[string]string{}
+	if err := flattenConfigFile(path, "", doc, paths, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenConfigFile(file string, prefix string, node map[string]any, paths map[string]string, values map[string]string) error {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fieldPath := key
+		if prefix != "" {
+			fieldPath = prefix + "." + key
+		}
+		if section, ok := node[key].(map[string]any); ok {
+			if err := flattenConfigFile(file, fieldPath, section, paths, values); err != nil {
+				return err
+			}
+			continue
+		}
+		name, ok := paths[fieldPath]
+		if !ok {
+			return errors.New(file + ": unknown field " + fieldPath)
+		}
+		switch v := node[key].(type) {
+		case nil:
+		case string:
+			values[name] = v
+		case json.Number:
+			values[name] = v.String()
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		default:
+			return errors.New(file + ": field " + fieldPath + " must be a string, number or boolean")
+		}
+	}
+	return nil
+}
+`,
+	},
+	"readConfigDir": {
+		imports: []string{`"errors"`, `"os"`, `"path/filepath"`, `"strings"`},
+		source: `// readConfigDir reads a directory with one file per variable, such as a
+// mounted ConfigMap or Secret. A variable is read from the file named after
+// it, its lowercased form or its lowercased form with dots as separators,
+// e.g. APP_DB_URL, app_db_url or app.db.url. Trailing newlines are trimmed,
+// and a directory that does not exist is skipped.
+func readConfigDir(dir string, names []string) (map[string]string, error) {
+	values := map[string]string{}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		dotted := strings.NewReplacer("_", ".", "-", ".").Replace(lower)
+		for _, file := range []string{name, lower, dotted} {
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[name] = strings.TrimRight(string(data), "\r\n")
+			break
+		}
+	}
+	return values, nil
+}
+`,
+	},
+	"resolveSecrets": {
+		imports: []string{`"context"`, `"strings"`, `"time"`},
+		source: `// resolveSecrets looks up names once and returns the values of those that
+// are set, with secret references resolved concurrently and within timeout,
+// and the names whose values were secret references.
+// Resolvers that ignore the context are abandoned once the timeout expires.
+func resolveSecrets(resolver SecretResolver, timeout time.Duration, lookup func(string) (string, bool), names []string) (map[string]string, map[string]bool, error) {
+	values := map[string]string{}
+	secrets := map[string]bool{}
+	var pending []string
+	for _, name := range names {
+		if _, seen := values[name]; seen {
+			continue
+		}
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		values[name] = value
+		if strings.HasPrefix(value, secretRefScheme) {
+			pending = append(pending, name)
+			secrets[name] = true
+		}
+	}
+	if len(pending) == 0 {
+		return values, secrets, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	type result struct {
+		value string
+		err   error
+	}
+	results := make([]chan result, len(pending))
+	for i, name := range pending {
+		results[i] = make(chan result, 1)
+		go func(ch chan<- result, ref string) {
+			value, err := resolver.ResolveSecret(ctx, ref)
+			ch <- result{value: value, err: err}
+		}(results[i], values[name])
+	}
+
+	var errs []error
+	for i, name := range pending {
+		var r result
+		select {
+		case r = <-results[i]:
+		case <-ctx.Done():
+			select {
+			case r = <-results[i]:
+			default:
+				r.err = ctx.Err()
+			}
+		}
+		if r.err != nil {
+			errs = append(errs, secretError{envVar: name, ref: values[name], err: r.err})
+		} else {
+			values[name] = r.value
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, UnresolvedSecretsError{vars: errs}
+	}
+	return values, secrets, nil
+}
+`,
+	},
+	"newExpander": {
+		imports: []string{`"strings"`},
+		source: `// expander expands ${VAR} and ${VAR:-fallback} in values and defaults, with
+// $$ standing for a literal $. The fallback is used when VAR is unset or
+// empty. Referenced variables are expanded in turn, falling back to their
+// defaults, and cycles are recorded instead of recursing forever.
+type expander struct {
+	lookup   func(string) (string, bool)
+	defaults map[string]string
+	active   []string
+	cycles   []error
+	reported map[string]bool
+}
+
+func newExpander(lookup func(string) (string, bool), defaults map[string]string) *expander {
+	return &expander{lookup: lookup, defaults: defaults, reported: map[string]bool{}}
+}
+
+// lookupExpanded has the same contract as os.LookupEnv and expands the
+// value of name.
+func (e *expander) lookupExpanded(name string) (string, bool) {
+	raw, ok := e.lookup(name)
+	if !ok {
+		return "", false
+	}
+	return e.expandValueOf(name, raw), true
+}
+
+func (e *expander) expandDefault(name string) string {
+	return e.expandValueOf(name, e.defaults[name])
+}
+
+// value returns the expanded value of a referenced variable, or of its
+// default if it is not set.
+func (e *expander) value(name string) (string, bool) {
+	raw, ok := e.lookup(name)
+	if !ok {
+		raw, ok = e.defaults[name]
+	}
+	if !ok {
+		return "", false
+	}
+	return e.expandValueOf(name, raw), true
+}
+
+func (e *expander) expandValueOf(name string, raw string) string {
+	for i, active := range e.active {
+		if active != name {
+			continue
+		}
+		root := e.active[0]
+		if !e.reported[root] {
+			e.reported[root] = true
+			chain := strings.Join(append(e.active[i:len(e.active):len(e.active)], name), " -> ")
+			e.cycles = append(e.cycles, cycleError{envVar: root, chain: chain})
+		}
+		return ""
+	}
+	if !strings.Contains(raw, "$") {
+		return raw
+	}
+	e.active = append(e.active, name)
+	defer func() {
+		e.active = e.active[:len(e.active)-1]
+	}()
+	return e.expand(raw)
+}
+
+func (e *expander) expand(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end, depth := -1, 0
+			for j := i + 1; j < len(s) && end < 0; j++ {
+				switch s[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+					if depth == 0 {
+						end = j
+					}
+				}
+			}
+			if end < 0 {
+				// an unterminated reference is kept as it is
+				sb.WriteString(s[i:])
+				return sb.String()
+			}
+			name, fallback, hasFallback := strings.Cut(s[i+2:end], ":-")
+			value, ok := e.value(name)
+			if hasFallback && (!ok || value == "") {
+				value = e.expand(fallback)
+			}
+			sb.WriteString(value)
+			i = end
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String()
+}
+
+type cycleError struct {
+	envVar string
+	chain  string
+}
+
+func (e cycleError) Error() string {
+	return e.envVar + " (" + e.chain + ")"
+}
+`,
+	},
+	"newEnvIndex": {
+		imports: []string{`"strings"`},
+		source: `// envIndex resolves env var names regardless of case. It maps upper-cased
+// names to every variable set under that name.
+type envIndex map[string][]envEntry
+
+type envEntry struct {
+	name  string
+	value string
+}
+
+func newEnvIndex(environ []string) envIndex {
+	idx := envIndex{}
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if name == "" {
+			continue
+		}
+		idx.add(name, value)
+	}
+	return idx
+}
+
+func newEnvIndexFromMap(m map[string]string) envIndex {
+	idx := envIndex{}
+	for name, value := range m {
+		idx.add(name, value)
+	}
+	return idx
+}
+
+func (idx envIndex) add(name, value string) {
+	key := strings.ToUpper(name)
+	idx[key] = append(idx[key], envEntry{name: name, value: value})
+}
+
+// lookup returns the value of the variable matching name regardless of
+// case, preferring an exact match.
+func (idx envIndex) lookup(name string) (string, bool) {
+	entries := idx[strings.ToUpper(name)]
+	for _, e := range entries {
+		if e.name == name {
+			return e.value, true
+		}
+	}
+	if len(entries) > 0 {
+		return entries[0].value, true
+	}
+	return "", false
+}
+
+// ambiguous reports whether any of names is set more than once with
+// different case.
+func (idx envIndex) ambiguous(names ...string) bool {
+	for _, name := range names {
+		if len(idx[strings.ToUpper(name)]) > 1 {
+			return true
+		}
+	}
+	return false
+}
+`,
+	},
+	"lookupEnvWithAliases": {
+		source: `// lookupEnvWithAliases returns the value of the first of names that is set
+// and the name it was read from. It also reports whether two of the names
+// are set to different values. Unless unset is nil, the values it reports
+// true for are skipped as if the name was not set.
+func lookupEnvWithAliases(lookup func(string) (string, bool), unset func(string) bool, names ...string) (value string, from string, ok bool, conflict bool) {
+	for _, name := range names {
+		v, set := lookup(name)
+		if !set || unset != nil && unset(v) {
+			continue
+		}
+		if !ok {
+			value, from, ok = v, name, true
+		} else if v != value {
+			conflict = true
+		}
+	}
+	return value, from, ok, conflict
+}
+`,
+	},
+	"layerLookups": {
+		source: `// layerLookups combines sources, earlier ones taking precedence. The names of
+// each group in aliases belong to one field and are all read from the first
+// source that sets any of them, so that a source wins outright instead of
+// mixing its value with the aliases set in the sources below it.
+func layerLookups(aliases [][]string, sources ...func(string) (string, bool)) func(string) (string, bool) {
+	groups := map[string][]string{}
+	for _, names := range aliases {
+		for _, name := range names {
+			groups[name] = names
+		}
+	}
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			if value, ok := source(name); ok {
+				return value, true
+			}
+			for _, alias := range groups[name] {
+				if alias == name {
+					continue
+				}
+				if _, ok := source(alias); ok {
+					return "", false
+				}
+			}
+		}
+		return "", false
+	}
+}
+`,
+	},
+	"validPort": {
+		imports: []string{`"strconv"`},
+		source: `// validPort reports whether s is a TCP or UDP port number, 1 to 65535.
+func validPort(s string) bool {
+	n, err := strconv.ParseUint(s, 10, 16)
+	return err == nil && n > 0
+}
+`,
+	},
+	"validPortNumber": {
+		source: `// validPortNumber reports whether n is a TCP or UDP port number, 1 to 65535.
+func validPortNumber(n int64) bool {
+	return n > 0 && n <= 65535
+}
+`,
+	},
+	"validHostname": {
+		imports: []string{`"strings"`},
+		source: `// validHostname reports whether s is a hostname as described by RFC 1123.
+// A single trailing dot is allowed.
+func validHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+`,
+	},
+	"validHostPort": {
+		imports: []string{`"net"`},
+		source: `// validHostPort reports whether s is a host:port address. The host may be
+// a hostname, an IP address or empty, as in the listen address ":8080".
+func validHostPort(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || !validPort(port) {
+		return false
+	}
+	return host == "" || net.ParseIP(host) != nil || validHostname(host)
+}
+`,
+	},
+	"validEmail": {
+		imports: []string{`"net/mail"`},
+		source: `// validEmail reports whether s is a bare email address, without a display
+// name or angle brackets.
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+`,
+	},
+	"validAbsURL": {
+		imports: []string{`"net/url"`},
+		source: `// validAbsURL reports whether s is an absolute URL with a scheme and a host.
+func validAbsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+`,
+	},
+	"filepathExists": {
+		imports: []string{`"os"`},
+		source: `// filepathExists reports whether s names an existing file that is not a
+// directory.
+func filepathExists(s string) bool {
+	info, err := os.Stat(s)
+	return err == nil && !info.IsDir()
+}
+`,
+	},
+	"dirExists": {
+		imports: []string{`"os"`},
+		source: `// dirExists reports whether s names an existing directory.
+func dirExists(s string) bool {
+	info, err := os.Stat(s)
+	return err == nil && info.IsDir()
+}
+`,
+	},
+	"newFieldError": {
+		imports: []string{`"errors"`, `"strconv"`},
+		source: `// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+`,
+	},
+	"parseExtendedDuration": {
+		imports: []string{`"errors"`, `"math"`, `"strconv"`, `"time"`},
+		source:  funcSource(durationsSource, "parseExtendedDuration"),
+	},
+	"parseISO8601Duration": {
+		imports: []string{`"errors"`, `"math"`, `"strconv"`, `"strings"`, `"time"`},
+		source:  funcSource(durationsSource, "parseISO8601Duration"),
+	},
+}
+
+// requiredHelpers returns the names of the helpers the fields need.
+func requiredHelpers(fields []TemplateData) []string {
+	var names []string
+	for _, f := range fields {
+		if _, ok := generatedHelpers[f.ParseFunc]; ok {
+			names = append(names, f.ParseFunc)
+		}
+		if len(f.Aliases) > 0 {
+			names = append(names, "lookupEnvWithAliases")
+		}
+		if f.FormatErr {
+			names = append(names, "newFieldError")
+		}
+		for _, v := range f.Validations {
+			names = append(names, v.Helpers...)
+		}
+	}
+	return names
+}
+
+// collectHelpers returns the source of the named helpers, each emitted once
+// and in a stable order, and registers the imports those helpers need.
+func collectHelpers(names []string, outputImports map[string]struct{}) string {
+	names = slices.Clone(names)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	sources := make([]string, 0, len(names))
+	for _, name := range names {
+		h := generatedHelpers[name]
+		for _, imp := range h.imports {
+			outputImports[imp] = struct{}{}
+		}
+		sources = append(sources, h.source)
+	}
+	return strings.Join(sources, "\n")
+}
@@ -0,0 +1,148 @@
+package genconfig
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Naming strategies control how the segments of a field path are turned into
+// environment variable names.
+const (
+	// NamingFlat upper-cases each segment and drops everything that is not a
+	// letter or a digit, e.g. ShutdownInterval becomes SHUTDOWNINTERVAL.
+	NamingFlat = "flat"
+	// NamingSnake splits each segment into words on camelCase and acronym
+	// boundaries, e.g. HTTPPort becomes HTTP_PORT.
+	NamingSnake = "snake"
+	// NamingKebab splits words like NamingSnake but produces lower-case names
+	// joined by dashes, e.g. http-port. The generated Go identifiers still use
+	// underscores.
+	NamingKebab = "kebab"
+)
+
+// envNaming bundles the settings used to turn field paths into env var names.
+type envNaming struct {
+	strategy  string
+	separator string // placed between path segments, words inside a segment are joined by the strategy
+	scope     string // config struct prepended to package-level names when configs share a package
+}
+
+func defaultSeparator(naming string) string {
+	if naming == NamingKebab {
+		return "-"
+	}
+	return "_"
+}
+
+func isValidSeparator(separator string) bool {
+	if separator == "" {
+		return false
+	}
+	for _, r := range separator {
+		if r != '_' && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func isValidNaming(naming string) bool {
+	switch naming {
+	case NamingFlat, NamingSnake, NamingKebab:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitWords splits a segment into words. A new word starts after any rune
+// that is not a letter or a digit, at a lower-case or digit to upper-case
+// transition, and before the last upper-case letter of an acronym that is
+// followed by a lower-case letter, so "HTTPPort" yields "HTTP" and "Port".
+func splitWords(segment string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+	runes := []rune(segment)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if len(current) > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+				flush()
+			} else if unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+// getWordSplitEnvKey builds env var names for the snake and kebab naming
+// strategies. Empty segments, such as an empty project prefix, are skipped.
+func getWordSplitEnvKey(canonicalNameList []string, naming envNaming) string {
+	wordSeparator := "_"
+	toCase := strings.ToUpper
+	if naming.strategy == NamingKebab {
+		wordSeparator = "-"
+		toCase = strings.ToLower
+	}
+	segments := make([]string, 0, len(canonicalNameList))
+	for _, part := range canonicalNameList {
+		words := splitWords(part)
+		if len(words) == 0 {
+			continue
+		}
+		segments = append(segments, toCase(strings.Join(words, wordSeparator)))
+	}
+	return strings.Join(segments, naming.separator)
+}
+
+// getWordSplitErrKey builds error variable names for the snake and kebab
+// naming strategies, capitalizing every word.
+func getWordSplitErrKey(canonicalNameList []string) string {
+	sb := &strings.Builder{}
+	sb.WriteString("Err")
+	for _, part := range canonicalNameList {
+		for _, word := range splitWords(part) {
+			runes := []rune(strings.ToLower(word))
+			runes[0] = unicode.ToUpper(runes[0])
+			sb.WriteString(string(runes))
+		}
+	}
+	sb.WriteString("Env")
+	return sb.String()
+}
+
+// getEnvConstName returns the name of the generated constant holding the env
+// var name. Env var names may contain dashes or dots, which identifiers
+// cannot.
+func getEnvConstName(envKey string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(envKey)) + "_ENV"
+}
+
+// constName returns the name of the constant holding envKey, prefixed with
+// the scope if there is one, e.g. APICONFIG_APP_PORT_ENV.
+func (n envNaming) constName(envKey string) string {
+	name := getEnvConstName(envKey)
+	if n.scope != "" {
+		name = strings.ToUpper(n.scope) + "_" + name
+	}
+	return name
+}
+
+// errName prefixes an error key with the scope if there is one, e.g.
+// ErrAPIConfigAppPortEnv.
+func (n envNaming) errName(errKey string) string {
+	return "Err" + n.scope + strings.TrimPrefix(errKey, "Err")
+}
@@ -0,0 +1,56 @@
+package genconfig
+
+import (
+	"fmt"
+	"slices"
+)
+
+// profileEnvName is the last segment of the variable that selects the
+// defaults profile, e.g. APP_PROFILE for the prefix APP.
+const profileEnvName = "Profile"
+
+// ProfileData holds the defaults that a profile overrides.
+type ProfileData struct {
+	Name     string
+	Defaults []ProfileFieldDefault
+}
+
+type ProfileFieldDefault struct {
+	EnvConst string
+	Raw      string
+}
+
+// collectProfiles groups the profile defaults of all fields by profile, in
+// alphabetical order of the profiles and in field order within a profile.
+func collectProfiles(fields []TemplateData) []ProfileData {
+	byName := map[string]*ProfileData{}
+	var names []string
+	for _, f := range fields {
+		for _, p := range f.ProfileDefaults {
+			profile, ok := byName[p.Profile]
+			if !ok {
+				profile = &ProfileData{Name: p.Profile}
+				byName[p.Profile] = profile
+				names = append(names, p.Profile)
+			}
+			profile.Defaults = append(profile.Defaults, ProfileFieldDefault{EnvConst: f.EnvConst, Raw: p.Raw})
+		}
+	}
+	slices.Sort(names)
+	profiles := make([]ProfileData, 0, len(names))
+	for _, name := range names {
+		profiles = append(profiles, *byName[name])
+	}
+	return profiles
+}
+
+// checkProfileEnv reports a field that would read the variable selecting
+// the profile as its own value.
+func checkProfileEnv(fields []TemplateData, envVar string, envConst string) error {
+	for _, f := range fields {
+		if f.EnvVar == envVar || f.EnvConst == envConst || slices.Contains(f.Aliases, envVar) {
+			return fmt.Errorf("env var %s selects the defaults profile and cannot be used by %s (%s)", envVar, f.Name, f.Position)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package genconfig
+
+import (
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldTags holds the genconfig struct tags of a single field.
+type fieldTags struct {
+	hasDefault    bool
+	defaultRaw    string   // raw string from default:"..." tag
+	durationStyle string   // durationstyle:"..." tag, empty if not set
+	normalize     []string // functions from the normalize:"..." tag
+	env           string   // exact env var name from env:"..." tag
+	skip          bool     // env:"-"
+	envPrefix     string   // envprefix:"..." tag on struct-typed fields
+	hasEnvPrefix  bool     // set even if envprefix is empty, which flattens the section
+	aliases       []string // deprecated env var names from aliases:"..." tag
+	profiles      []ProfileDefault
+	min           string   // min:"..." bound for numbers and durations
+	max           string   // max:"..." bound for numbers and durations
+	minLen        string   // minlen:"..." for strings
+	maxLen        string   // maxlen:"..." for strings
+	pattern       string   // pattern:"..." regular expression for strings
+	validators    []string // built-in checks from the validate:"..." tag
+	nonEmpty      bool     // required:"nonempty", an empty value counts as unset
+	optional      bool     // optional:"true", an unset value leaves the zero value
+	requiredIf    string   // requiredif:"Field=value"
+	requiredWith  []string // requiredwith:"Field,..."
+	excludes      []string // excludes:"Field,..."
+}
+
+// ProfileDefault is a default that only applies when its profile is selected
+// at load time, from a default.<profile>:"..." tag.
+type ProfileDefault struct {
+	Profile string
+	Raw     string
+}
+
+func parseFieldTags(lit *ast.BasicLit) fieldTags {
+	var tags fieldTags
+	if lit == nil {
+		return tags
+	}
+	tag := reflect.StructTag(strings.Trim(lit.Value, "`"))
+	if raw, ok := tag.Lookup("default"); ok {
+		tags.hasDefault = true
+		tags.defaultRaw = raw
+	}
+	tags.durationStyle = tag.Get("durationstyle")
+	if raw, ok := tag.Lookup("normalize"); ok {
+		tags.normalize = lookupNormalizeFuncs(raw)
+	}
+	if raw, ok := tag.Lookup("env"); ok {
+		if raw == "-" {
+			tags.skip = true
+		} else {
+			if !isValidEnvName(raw) {
+				panic("env tag " + raw + " is not a valid environment variable name")
+			}
+			tags.env = raw
+		}
+	}
+	tags.envPrefix, tags.hasEnvPrefix = tag.Lookup("envprefix")
+	if raw, ok := tag.Lookup("aliases"); ok {
+		for _, alias := range strings.Split(raw, ",") {
+			alias = strings.TrimSpace(alias)
+			if !isValidEnvName(alias) {
+				panic("alias " + alias + " is not a valid environment variable name")
+			}
+			tags.aliases = append(tags.aliases, alias)
+		}
+	}
+	tags.profiles = parseProfileDefaults(string(tag))
+	tags.min = tag.Get("min")
+	tags.max = tag.Get("max")
+	tags.minLen = tag.Get("minlen")
+	tags.maxLen = tag.Get("maxlen")
+	tags.pattern = tag.Get("pattern")
+	for _, name := range strings.Split(tag.Get("validate"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			tags.validators = append(tags.validators, name)
+		}
+	}
+	if raw, ok := tag.Lookup("required"); ok {
+		if raw != "nonempty" {
+			panic("required tag " + strconv.Quote(raw) + " is not supported, only required:\"nonempty\" is")
+		}
+		tags.nonEmpty = true
+	}
+	if raw, ok := tag.Lookup("optional"); ok {
+		optional, err := strconv.ParseBool(raw)
+		if err != nil {
+			panic("optional tag " + strconv.Quote(raw) + " must be a boolean")
+		}
+		tags.optional = optional
+	}
+	tags.requiredIf = tag.Get("requiredif")
+	tags.requiredWith = splitFieldList(tag.Get("requiredwith"))
+	tags.excludes = splitFieldList(tag.Get("excludes"))
+	if tags.optional && tags.hasDefault {
+		panic("optional tag is redundant on a field with a default")
+	}
+	return tags
+}
+
+// leafOnlyTag returns the name of the first tag that only makes sense on
+// leaf fields, or an empty string if there is none. It is used to reject
+// those tags on struct-typed fields instead of silently ignoring them.
+func (t fieldTags) leafOnlyTag() string {
+	switch {
+	case t.hasDefault:
+		return "default"
+	case t.durationStyle != "":
+		return "durationstyle"
+	case len(t.normalize) > 0:
+		return "normalize"
+	case t.env != "":
+		return "env"
+	case len(t.aliases) > 0:
+		return "aliases"
+	case len(t.profiles) > 0:
+		return "default." + t.profiles[0].Profile
+	case t.min != "":
+		return "min"
+	case t.max != "":
+		return "max"
+	case t.minLen != "":
+		return "minlen"
+	case t.maxLen != "":
+		return "maxlen"
+	case t.pattern != "":
+		return "pattern"
+	case len(t.validators) > 0:
+		return "validate"
+	case t.nonEmpty:
+		return "required"
+	case t.optional:
+		return "optional"
+	case t.requiredIf != "":
+		return "requiredif"
+	case len(t.requiredWith) > 0:
+		return "requiredwith"
+	case len(t.excludes) > 0:
+		return "excludes"
+	default:
+		return ""
+	}
+}
+
+// splitFieldList splits a comma separated list of field paths.
+func splitFieldList(raw string) []string {
+	var paths []string
+	for _, path := range strings.Split(raw, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// isValidEnvName reports whether name can be used both as an environment
+// variable and as part of the generated constant name.
+func isValidEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'A' <= r && r <= 'Z', 'a' <= r && r <= 'z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseProfileDefaults returns the default.<profile>:"..." tags in the order
+// they are written. reflect.StructTag can only look up known keys, so the
+// tag is scanned the same way reflect does it.
+func parseProfileDefaults(tag string) []ProfileDefault {
+	var profiles []ProfileDefault
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		quoted := tag[:i+1]
+		tag = tag[i+1:]
+
+		profile, ok := strings.CutPrefix(key, "default.")
+		if !ok {
+			continue
+		}
+		if !isValidProfileName(profile) {
+			panic("profile " + strconv.Quote(profile) + " in tag " + key + " must only contain letters, digits, '_' and '-'")
+		}
+		raw, err := strconv.Unquote(quoted)
+		if err != nil {
+			panic("could not parse tag " + key + ": " + err.Error())
+		}
+		for _, p := range profiles {
+			if p.Profile == profile {
+				panic("tag " + key + " is set more than once")
+			}
+		}
+		profiles = append(profiles, ProfileDefault{Profile: profile, Raw: raw})
+	}
+	return profiles
+}
+
+func isValidProfileName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r == '_', r == '-', 'A' <= r && r <= 'Z', 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
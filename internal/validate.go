@@ -0,0 +1,162 @@
+package genconfig
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validation is a constraint checked on a field once its value is parsed.
+type Validation struct {
+	Cond       string   // Go expression that is true when the constraint is violated
+	Constraint string   // the failed constraint as reported at load time, e.g. min 1
+	Helpers    []string // generated helpers that Cond calls
+}
+
+// semanticValidator is a built-in check of the validate:"..." tag, done by a
+// generated helper that reports whether a string value is valid.
+type semanticValidator struct {
+	helper    string
+	intHelper string   // check for integer fields, empty if only strings are supported
+	dependsOn []string // helpers that helper calls
+}
+
+var semanticValidators = map[string]semanticValidator{
+	"port":            {helper: "validPort", intHelper: "validPortNumber"},
+	"hostport":        {helper: "validHostPort", dependsOn: []string{"validPort", "validHostname"}},
+	"hostname":        {helper: "validHostname"},
+	"email":           {helper: "validEmail"},
+	"absurl":          {helper: "validAbsURL"},
+	"filepath-exists": {helper: "filepathExists"},
+	"dir-exists":      {helper: "dirExists"},
+}
+
+// buildValidations turns the min, max, minlen, maxlen, pattern and validate
+// tags of a field into checks on config.<name>. Bounds are parsed here so that a
+// malformed or out of range bound fails generation instead of compilation. The
+// pattern is matched with patternVar, compiled once at package level.
+func buildValidations(name string, typ string, bitSize int, parseFunc string, patternVar string, tags fieldTags, outputImports map[string]struct{}) []Validation {
+	var validations []Validation
+	value := "config." + name
+
+	if tags.min != "" || tags.max != "" {
+		parse := numericBoundParser(typ, bitSize, parseFunc)
+		if parse == nil {
+			panic("min and max tags are only supported on numeric and time.Duration fields, got " + typ + " for field " + name)
+		}
+		parseBound := func(tag, raw string) (string, float64) {
+			lit, v, err := parse(raw)
+			if err != nil {
+				panic("invalid " + tag + " tag " + strconv.Quote(raw) + " for field " + name + ": " + err.Error())
+			}
+			return lit, v
+		}
+		var minValue, maxValue float64
+		if tags.min != "" {
+			lit, v := parseBound("min", tags.min)
+			minValue = v
+			validations = append(validations, Validation{Cond: value + " < " + lit, Constraint: "min " + tags.min})
+		}
+		if tags.max != "" {
+			lit, v := parseBound("max", tags.max)
+			maxValue = v
+			validations = append(validations, Validation{Cond: value + " > " + lit, Constraint: "max " + tags.max})
+		}
+		if tags.min != "" && tags.max != "" && minValue > maxValue {
+			panic("min " + tags.min + " is greater than max " + tags.max + " for field " + name)
+		}
+	}
+
+	if tags.minLen != "" || tags.maxLen != "" || tags.pattern != "" {
+		if typ != "string" {
+			panic("minlen, maxlen and pattern tags are only supported on string fields, got " + typ + " for field " + name)
+		}
+	}
+	length := "utf8.RuneCountInString(" + value + ")"
+	var minLen, maxLen int
+	if tags.minLen != "" {
+		minLen = parseLength("minlen", tags.minLen, name)
+		validations = append(validations, Validation{Cond: length + " < " + strconv.Itoa(minLen), Constraint: "minlen " + tags.minLen})
+		outputImports[`"unicode/utf8"`] = struct{}{}
+	}
+	if tags.maxLen != "" {
+		maxLen = parseLength("maxlen", tags.maxLen, name)
+		validations = append(validations, Validation{Cond: length + " > " + strconv.Itoa(maxLen), Constraint: "maxlen " + tags.maxLen})
+		outputImports[`"unicode/utf8"`] = struct{}{}
+	}
+	if tags.minLen != "" && tags.maxLen != "" && minLen > maxLen {
+		panic("minlen " + tags.minLen + " is greater than maxlen " + tags.maxLen + " for field " + name)
+	}
+	if tags.pattern != "" {
+		if _, err := regexp.Compile(tags.pattern); err != nil {
+			panic("invalid pattern for field " + name + ": " + err.Error())
+		}
+		validations = append(validations, Validation{Cond: "!" + patternVar + ".MatchString(" + value + ")", Constraint: "pattern " + tags.pattern})
+		outputImports[`"regexp"`] = struct{}{}
+	}
+	for _, check := range tags.validators {
+		v, ok := semanticValidators[check]
+		if !ok {
+			panic("validate tag " + strconv.Quote(check) + " is not supported for field " + name)
+		}
+		helper, arg := v.helper, value
+		if typ != "string" {
+			if v.intHelper == "" || !(strings.HasPrefix(typ, "int") || strings.HasPrefix(typ, "uint")) {
+				panic("validate tag " + strconv.Quote(check) + " is not supported on " + typ + " fields, got field " + name)
+			}
+			helper, arg = v.intHelper, "int64("+value+")"
+		}
+		validations = append(validations, Validation{
+			Cond:       "!" + helper + "(" + arg + ")",
+			Constraint: check,
+			Helpers:    append([]string{helper}, v.dependsOn...),
+		})
+	}
+	return validations
+}
+
+// numericBoundParser returns a function that parses a value of typ into a
+// Go literal and its numeric value, or nil if typ has no order. Durations are
+// parsed like the values of the field, with parseFunc.
+func numericBoundParser(typ string, bitSize int, parseFunc string) func(raw string) (string, float64, error) {
+	switch {
+	case typ == "time.Duration":
+		parseDuration := time.ParseDuration
+		switch parseFunc {
+		case "parseExtendedDuration":
+			parseDuration = parseExtendedDuration
+		case "parseISO8601Duration":
+			parseDuration = parseISO8601Duration
+		}
+		return func(raw string) (string, float64, error) {
+			d, err := parseDuration(raw)
+			return strconv.FormatInt(int64(d), 10), float64(d), err
+		}
+	case strings.HasPrefix(typ, "int"):
+		return func(raw string) (string, float64, error) {
+			v, err := strconv.ParseInt(raw, 10, bitSize)
+			return strconv.FormatInt(v, 10), float64(v), err
+		}
+	case strings.HasPrefix(typ, "uint"):
+		return func(raw string) (string, float64, error) {
+			v, err := strconv.ParseUint(raw, 10, bitSize)
+			return strconv.FormatUint(v, 10), float64(v), err
+		}
+	case strings.HasPrefix(typ, "float"):
+		return func(raw string) (string, float64, error) {
+			v, err := strconv.ParseFloat(raw, bitSize)
+			return strconv.FormatFloat(v, 'g', -1, bitSize), v, err
+		}
+	default:
+		return nil
+	}
+}
+
+func parseLength(tag string, raw string, name string) int {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		panic("invalid " + tag + " tag " + strconv.Quote(raw) + " for field " + name + ": must be a non-negative integer")
+	}
+	return n
+}
@@ -0,0 +1,113 @@
+package genconfig
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ValidateCall is a section of the config whose type has a Validate() error
+// method, called once all fields parse.
+type ValidateCall struct {
+	Path string // field path of the section, empty for the config struct itself
+}
+
+// findValidateMethods returns the names of the types that declare a
+// Validate() error method, with a value or pointer receiver, in the package
+// of inputFile. The file being generated and test files are not considered.
+func findValidateMethods(inputNode *ast.File, inputFile string, outputFile string, debug bool) map[string]bool {
+	types := map[string]bool{}
+	collect := func(file *ast.File) {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Name.Name != "Validate" {
+				continue
+			}
+			if fn.Type.Params.NumFields() != 0 || fn.Type.Results.NumFields() != 1 {
+				continue
+			}
+			if result, ok := fn.Type.Results.List[0].Type.(*ast.Ident); !ok || result.Name != "error" {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				types[ident.Name] = true
+			}
+		}
+	}
+	collect(inputNode)
+
+	inputAbs, _ := filepath.Abs(inputFile)
+	outputAbs, _ := filepath.Abs(outputFile)
+	paths, _ := filepath.Glob(filepath.Join(filepath.Dir(inputFile), "*.go"))
+	for _, path := range paths {
+		abs, _ := filepath.Abs(path)
+		if abs == inputAbs || abs == outputAbs || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			printline(debug, "skipping", path, "when looking for Validate methods:", err)
+			continue
+		}
+		if file.Name.Name != inputNode.Name.Name {
+			continue
+		}
+		collect(file)
+	}
+	return types
+}
+
+// collectValidateCalls returns the sections of the config whose types have a
+// Validate method, innermost sections first and the config struct last.
+// Sections skipped with env:"-" are not loaded and thus not validated.
+func collectValidateCalls(structName string, allTopLevelStructDefinitions map[string]*ast.StructType, hasValidate map[string]bool) []ValidateCall {
+	type section struct {
+		path  string
+		depth int
+	}
+	var sections []section
+	var walk func(def *ast.StructType, path []string)
+	walk = func(def *ast.StructType, path []string) {
+		if def.Fields == nil {
+			return
+		}
+		for _, f := range def.Fields.List {
+			// embedded fields are not loaded, see insertTemplateDataEntryForStruct
+			if len(f.Names) == 0 {
+				continue
+			}
+			typ := convertTypeIdentifierToString(f.Type)
+			child, ok := allTopLevelStructDefinitions[typ]
+			if !ok || parseFieldTags(f.Tag).skip {
+				continue
+			}
+			for _, n := range f.Names {
+				childPath := append(slices.Clip(path), n.Name)
+				walk(child, childPath)
+				if hasValidate[typ] {
+					sections = append(sections, section{path: strings.Join(childPath, "."), depth: len(childPath)})
+				}
+			}
+		}
+	}
+	walk(allTopLevelStructDefinitions[structName], nil)
+	if hasValidate[structName] {
+		sections = append(sections, section{})
+	}
+	slices.SortStableFunc(sections, func(a, b section) int {
+		return b.depth - a.depth
+	})
+
+	calls := make([]ValidateCall, 0, len(sections))
+	for _, s := range sections {
+		calls = append(calls, ValidateCall{Path: s.path})
+	}
+	return calls
+}
@@ -14,6 +14,7 @@ const (
 	defaultOutputConfigLoader = "config_gen.go"
 	defaultConfigStructName   = "Config"
 	defaultProjectName        = ""
+	defaultNaming             = gncfg.NamingFlat
 )
 
 var (
@@ -23,6 +24,19 @@ var (
 	flagProjectName      string
 	flagConfigFilePath   string
 	flagDebugLogs        bool
+	flagNaming           string
+	flagSeparator        string
+	flagCaseInsensitive  bool
+	flagEmptyIsUnset     bool
+	flagDotenv           bool
+	flagJSONFile         bool
+	flagConfigDir        bool
+	flagFlags            bool
+	flagSecrets          bool
+	flagInterpolation    bool
+	flagErrorReport      bool
+	flagOutputLoader     string
+	flagRuntimeFile      string
 )
 
 func main() {
@@ -31,6 +45,19 @@ func main() {
 	flag.StringVar(&flagConfigStructName, "struct", defaultConfigStructName, "Name of the config struct.")
 	flag.StringVar(&flagConfigFilePath, "path", "", "File path of the config struct. Defaults to the location of the go:generate directive. Note that because of this, running genconfig as an executable without providing this flag can behave unpredictably.")
 	flag.StringVar(&flagOutputDotenvFile, "env", defaultOutputDotenv, "Name of the output .env file, if you want to generate one with all possible config values. An empty value will not generate a .env file.")
+	flag.StringVar(&flagNaming, "naming", defaultNaming, "Strategy used to build environment variable names from field names. One of flat (SHUTDOWNINTERVAL), snake (SHUTDOWN_INTERVAL) or kebab (shutdown-interval).")
+	flag.StringVar(&flagSeparator, "separator", "", "Separator placed between nested sections of environment variable names, e.g. __ for APP__SERVER__PORT. Defaults to - for kebab naming and _ otherwise.")
+	flag.BoolVar(&flagCaseInsensitive, "case-insensitive", false, "Resolve environment variables regardless of case. Variables set more than once with different case are reported as ambiguous.")
+	flag.BoolVar(&flagEmptyIsUnset, "empty-unset", false, "Treat environment variables that are set to an empty string as unset, so that they fall back to their default or are reported as missing.")
+	flag.BoolVar(&flagDotenv, "dotenv", false, "Generate the WithDotenv load option, which reads .env files at runtime and layers them under the environment.")
+	flag.BoolVar(&flagJSONFile, "json-file", false, "Generate Load<Struct>WithFile and the WithConfigFile load option, which read a JSON config file at runtime and layer it under the environment.")
+	flag.BoolVar(&flagConfigDir, "config-dir", false, "Generate the WithConfigDir load option, which reads a directory of files named after env vars, such as a mounted ConfigMap, and layers it under the environment.")
+	flag.BoolVar(&flagFlags, "flags", false, "Generate Register<Struct>Flags, Load<Struct>WithFlags and the WithFlags load option, which give command line flags precedence over the environment.")
+	flag.BoolVar(&flagSecrets, "secrets", false, "Resolve secretref:// values at runtime through a SecretResolver, and generate the WithSecretResolver and WithSecretTimeout load options.")
+	flag.BoolVar(&flagInterpolation, "interpolate", false, "Expand ${VAR}, ${VAR:-fallback} and $$ in values and defaults at runtime. A literal $ must then be written as $$.")
+	flag.BoolVar(&flagErrorReport, "error-report", false, "Generate FormatConfigError, which formats load errors as a table with the type, default and description of each field.")
+	flag.StringVar(&flagOutputLoader, "out", defaultOutputConfigLoader, "Name of the generated config loader file. Give each config of a package its own file.")
+	flag.StringVar(&flagRuntimeFile, "runtime", "", "Name of the file that receives the types and helpers shared by every config of the package, e.g. genconfig_runtime.go. Required to generate more than one config in a package, each with the same value.")
 	flag.BoolVar(&flagDebugLogs, "debug", false, "Debug generation issues.")
 	flag.BoolVar(&flagPrintUsage, "help", false, "Show usage.")
 
@@ -47,11 +74,12 @@ func main() {
 	// don't want to provide a path for the output (the reverse doesn't really
 	// make a reasonable use case). In that case, detect only the input path
 	// was specified, and appent that path's parent to the output too.
-	//
-	// For now, the generated config name is not configurable, but will be
-	// in the future.
 	parent, _ := filepath.Split(flagConfigFilePath)
-	outputConfigLoaderPath := parent + defaultOutputConfigLoader
+	outputConfigLoaderPath := parent + flagOutputLoader
+	var runtimeFilePath string
+	if flagRuntimeFile != "" {
+		runtimeFilePath = parent + flagRuntimeFile
+	}
 
 	var configFilePath string
 	if flagConfigFilePath != "" {
@@ -72,7 +100,21 @@ func main() {
 
 	fmt.Printf("Using the struct %s from file %s\n", flagConfigStructName, configFilePath)
 
-	err := gncfg.GenerateConfigLoader(flagProjectName, flagConfigStructName, configFilePath, outputConfigLoaderPath, flagOutputDotenvFile, "", flagDebugLogs)
+	opts := gncfg.Options{
+		Naming:          flagNaming,
+		Separator:       flagSeparator,
+		CaseInsensitive: flagCaseInsensitive,
+		EmptyIsUnset:    flagEmptyIsUnset,
+		Dotenv:          flagDotenv,
+		JSONFile:        flagJSONFile,
+		ConfigDir:       flagConfigDir,
+		Flags:           flagFlags,
+		Secrets:         flagSecrets,
+		Interpolation:   flagInterpolation,
+		ErrorReport:     flagErrorReport,
+		RuntimeFile:     runtimeFilePath,
+	}
+	err := gncfg.GenerateConfigLoaderWithOptions(flagProjectName, flagConfigStructName, configFilePath, outputConfigLoaderPath, flagOutputDotenvFile, "", flagDebugLogs, opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "could not generate config: %s", err.Error())
 		os.Exit(1)
@@ -5,8 +5,11 @@
 package t1
 
 import (
+	"encoding/json"
 	"errors"
+	"flag"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -29,45 +32,129 @@ var (
 	ErrTestconfig1RetriesEnvInvalid = errors.New(TESTCONFIG1_RETRIES_ENV)
 )
 
-func LoadTestConfig1() (TestConfig1, error) {
+// LoadTestConfig1 reads the config from the environment.
+func LoadTestConfig1(opts ...LoadOption) (TestConfig1, error) {
+	return LoadTestConfig1From(os.LookupEnv, opts...)
+}
+
+// LoadTestConfig1WithFile reads the config from the environment, falling
+// back to the JSON config file at path and then to the defaults.
+func LoadTestConfig1WithFile(path string, opts ...LoadOption) (TestConfig1, error) {
+	return LoadTestConfig1(append(opts, WithConfigFile(path))...)
+}
+
+// LoadTestConfig1WithFlags reads the config from the flags set on fs,
+// falling back to the environment and then to the defaults. The flags must
+// have been registered with RegisterTestConfig1Flags and fs must be parsed.
+func LoadTestConfig1WithFlags(fs *flag.FlagSet, opts ...LoadOption) (TestConfig1, error) {
+	return LoadTestConfig1(append(opts, WithFlags(fs))...)
+}
+
+// RegisterTestConfig1Flags defines a flag on fs for every field of
+// TestConfig1, named after the lowercased field path, e.g. -server.port.
+// Values are parsed when the config is loaded, like env vars.
+func RegisterTestConfig1Flags(fs *flag.FlagSet) {
+	fs.Var(&flagValue{envVar: TESTCONFIG1_APPNAME_ENV}, "appname", "env TESTCONFIG1_APPNAME")
+	fs.Var(&flagValue{envVar: TESTCONFIG1_DEBUG_ENV, isBool: true}, "debug", "env TESTCONFIG1_DEBUG")
+	fs.Var(&flagValue{envVar: TESTCONFIG1_TIMEOUT_ENV}, "timeout", "env TESTCONFIG1_TIMEOUT")
+	fs.Var(&flagValue{envVar: TESTCONFIG1_RETRIES_ENV}, "retries", "env TESTCONFIG1_RETRIES")
+}
+
+// LoadTestConfig1FromMap reads the config from m instead of the
+// environment.
+func LoadTestConfig1FromMap(m map[string]string, opts ...LoadOption) (TestConfig1, error) {
+	return LoadTestConfig1From(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfig1From reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfig1From(lookup func(string) (string, bool), opts ...LoadOption) (TestConfig1, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	sources := []func(string) (string, bool){lookup}
+	if len(options.dotenvFiles) > 0 {
+		values, err := readDotenvFiles(options.dotenvFiles, false)
+		if err != nil {
+			return TestConfig1{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+	if options.configFile != "" {
+		values, err := readConfigFile(options.configFile, map[string]string{
+			"AppName": TESTCONFIG1_APPNAME_ENV,
+			"Debug":   TESTCONFIG1_DEBUG_ENV,
+			"Timeout": TESTCONFIG1_TIMEOUT_ENV,
+			"Retries": TESTCONFIG1_RETRIES_ENV,
+		})
+		if err != nil {
+			return TestConfig1{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+
+	if options.flagSet != nil {
+		flagValues := map[string]string{}
+		options.flagSet.Visit(func(f *flag.Flag) {
+			if v, ok := f.Value.(*flagValue); ok {
+				flagValues[v.envVar] = v.value
+			}
+		})
+		sources = append([]func(string) (string, bool){func(name string) (string, bool) {
+			value, ok := flagValues[name]
+			return value, ok
+		}}, sources...)
+	}
+	lookup = layerLookups(nil, sources...)
+
 	var config TestConfig1
 	var missingVars []error
 	var formatVars []error
-	val_AppName, ok := os.LookupEnv(TESTCONFIG1_APPNAME_ENV)
+	val_AppName, ok := lookup(TESTCONFIG1_APPNAME_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfig1AppnameEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "AppName", EnvVar: TESTCONFIG1_APPNAME_ENV, Kind: "missing", sentinel: ErrTestconfig1AppnameEnvMissing})
 	} else {
 		config.AppName = val_AppName
 	}
-	val_Debug, ok := os.LookupEnv(TESTCONFIG1_DEBUG_ENV)
+	val_Debug, ok := lookup(TESTCONFIG1_DEBUG_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfig1DebugEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Debug", EnvVar: TESTCONFIG1_DEBUG_ENV, Kind: "missing", sentinel: ErrTestconfig1DebugEnvMissing})
 	} else {
 		parsed, err := strconv.ParseBool(val_Debug)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfig1DebugEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Debug", TESTCONFIG1_DEBUG_ENV, ErrTestconfig1DebugEnvInvalid, val_Debug, false, err))
 		} else {
 			config.Debug = parsed
 		}
 	}
-	val_Timeout, ok := os.LookupEnv(TESTCONFIG1_TIMEOUT_ENV)
+	val_Timeout, ok := lookup(TESTCONFIG1_TIMEOUT_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfig1TimeoutEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Timeout", EnvVar: TESTCONFIG1_TIMEOUT_ENV, Kind: "missing", sentinel: ErrTestconfig1TimeoutEnvMissing})
 	} else {
 		parsed, err := time.ParseDuration(val_Timeout)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfig1TimeoutEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Timeout", TESTCONFIG1_TIMEOUT_ENV, ErrTestconfig1TimeoutEnvInvalid, val_Timeout, false, err))
 		} else {
 			config.Timeout = parsed
 		}
 	}
-	val_Retries, ok := os.LookupEnv(TESTCONFIG1_RETRIES_ENV)
+	val_Retries, ok := lookup(TESTCONFIG1_RETRIES_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfig1RetriesEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Retries", EnvVar: TESTCONFIG1_RETRIES_ENV, Kind: "missing", sentinel: ErrTestconfig1RetriesEnvMissing})
 	} else {
 		parsed, err := strconv.Atoi(val_Retries)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfig1RetriesEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Retries", TESTCONFIG1_RETRIES_ENV, ErrTestconfig1RetriesEnvInvalid, val_Retries, false, err))
 		} else {
 			config.Retries = parsed
 		}
@@ -87,6 +174,133 @@ func LoadTestConfig1() (TestConfig1, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning   func(Warning)
+	dotenvFiles []string
+	configFile  string
+	flagSet     *flag.FlagSet
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+// WithDotenv layers the values of dotenv files under the real environment.
+// Later files take precedence over earlier ones, and files that do not
+// exist are skipped, e.g. WithDotenv(".env", ".env.local").
+func WithDotenv(paths ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenvFiles = append(o.dotenvFiles, paths...)
+	}
+}
+
+// WithConfigFile layers the values of a JSON config file under the real
+// environment and any dotenv files. The document mirrors the nesting of
+// the config struct, keyed by field name, e.g. {"Server": {"Port": 8080}}.
+func WithConfigFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.configFile = path
+	}
+}
+
+// WithFlags gives the flags set on fs precedence over every other source.
+// fs must be parsed and its flags registered with the Register...Flags
+// function of the config.
+func WithFlags(fs *flag.FlagSet) LoadOption {
+	return func(o *loadOptions) {
+		o.flagSet = fs
+	}
+}
+
+// flagValue holds the raw value of a flag, which is parsed together with
+// the other sources of envVar.
+type flagValue struct {
+	envVar string
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -96,14 +310,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -115,12 +322,264 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// layerLookups combines sources, earlier ones taking precedence. The names of
+// each group in aliases belong to one field and are all read from the first
+// source that sets any of them, so that a source wins outright instead of
+// mixing its value with the aliases set in the sources below it.
+func layerLookups(aliases [][]string, sources ...func(string) (string, bool)) func(string) (string, bool) {
+	groups := map[string][]string{}
+	for _, names := range aliases {
+		for _, name := range names {
+			groups[name] = names
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			if value, ok := source(name); ok {
+				return value, true
+			}
+			for _, alias := range groups[name] {
+				if alias == name {
+					continue
+				}
+				if _, ok := source(alias); ok {
+					return "", false
+				}
+			}
+		}
+		return "", false
+	}
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// readConfigFile reads a JSON config file and returns its values keyed by
+// env var. paths maps the dotted path of every field to its env var; other
+// keys are rejected so that typos do not go unnoticed. Values are kept as
+// text and parsed like the environment, and null leaves a field unset.
+func readConfigFile(path string, paths map[string]string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	values := map[string]string{}
+	if err := flattenConfigFile(path, "", doc, paths, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenConfigFile(file string, prefix string, node map[string]any, paths map[string]string, values map[string]string) error {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fieldPath := key
+		if prefix != "" {
+			fieldPath = prefix + "." + key
+		}
+		if section, ok := node[key].(map[string]any); ok {
+			if err := flattenConfigFile(file, fieldPath, section, paths, values); err != nil {
+				return err
+			}
+			continue
+		}
+		name, ok := paths[fieldPath]
+		if !ok {
+			return errors.New(file + ": unknown field " + fieldPath)
+		}
+		switch v := node[key].(type) {
+		case nil:
+		case string:
+			values[name] = v
+		case json.Number:
+			values[name] = v.String()
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		default:
+			return errors.New(file + ": field " + fieldPath + " must be a string, number or boolean")
+		}
+	}
+	return nil
+}
+
+// readDotenvFiles reads and merges dotenv files, later files taking
+// precedence. Files that do not exist are skipped. escapeDollar is passed
+// on to parseDotenv.
+func readDotenvFiles(paths []string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := parseDotenv(path, string(data), escapeDollar)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// parseDotenv parses the contents of a dotenv file. It supports comments,
+// an optional export prefix, single quoted values (taken literally), double
+// quoted values (with \n, \r, \t, \", \\ and \$ escapes, possibly spanning
+// several lines) and unquoted values, which end at an inline " #" comment.
+// With escapeDollar, the literal dollars of \$ escapes and single quoted
+// values are written as $$, so that interpolation keeps them as they are.
+func parseDotenv(path string, data string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	line := 1
+	fail := func(msg string) error {
+		return errors.New(path + ":" + strconv.Itoa(line) + ": " + msg)
+	}
+	lineEnd := func(i int) int {
+		if end := strings.IndexByte(data[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(data)
+	}
+	i := 0
+	for i < len(data) {
+		switch data[i] {
+		case '\n':
+			line++
+			i++
+			continue
+		case ' ', '\t', '\r':
+			i++
+			continue
+		case '#':
+			i = lineEnd(i)
+			continue
+		}
+
+		end := lineEnd(i)
+		if rest, ok := strings.CutPrefix(data[i:end], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			i += len("export")
+		}
+		eq := strings.IndexByte(data[i:end], '=')
+		if eq < 0 {
+			return nil, fail("expected KEY=value")
+		}
+		key := strings.TrimSpace(data[i : i+eq])
+		if key == "" || strings.ContainsAny(key, " \t'\"#") {
+			return nil, fail("invalid key " + strconv.Quote(key))
+		}
+		i += eq + 1
+		for i < end && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+
+		if i < end && (data[i] == '\'' || data[i] == '"') {
+			quote := data[i]
+			i++
+			startLine := line
+			var sb strings.Builder
+			closed := false
+			for i < len(data) {
+				c := data[i]
+				if c == quote {
+					closed = true
+					i++
+					break
+				}
+				if quote == '"' && c == '\\' && i+1 < len(data) {
+					switch next := data[i+1]; next {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					case '$':
+						if escapeDollar {
+							sb.WriteByte('$')
+						}
+						sb.WriteByte('$')
+					case '"', '\\':
+						sb.WriteByte(next)
+					default:
+						sb.WriteByte(c)
+						sb.WriteByte(next)
+						if next == '\n' {
+							line++
+						}
+					}
+					i += 2
+					continue
+				}
+				if c == '\n' {
+					line++
+				}
+				if quote == '\'' && c == '$' && escapeDollar {
+					sb.WriteByte('$')
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				line = startLine
+				return nil, fail("unterminated quoted value for " + key)
+			}
+			end = lineEnd(i)
+			if rest := strings.TrimSpace(data[i:end]); rest != "" && rest[0] != '#' {
+				return nil, fail("unexpected characters after quoted value for " + key)
+			}
+			values[key] = sb.String()
+		} else {
+			raw := data[i:end]
+			if comment := strings.Index(raw, " #"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			if comment := strings.Index(raw, "\t#"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			values[key] = strings.TrimSpace(raw)
+		}
+		i = end
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return values, nil
 }
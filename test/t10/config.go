@@ -0,0 +1,16 @@
+//go:build testcases
+// +build testcases
+
+package t10
+
+type Internal struct {
+	Token string
+}
+
+type TestConfigEnvOverride struct {
+	DatabaseURL string   `env:"DATABASE_URL"`
+	Port        int      `env:"PORT" default:"8080"`
+	Name        string   // still uses the generated name
+	Computed    string   `env:"-"`
+	Internal    Internal `env:"-"`
+}
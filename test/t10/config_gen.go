@@ -0,0 +1,581 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t10
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DATABASE_URL_ENV               = "DATABASE_URL"
+	PORT_ENV                       = "PORT"
+	TESTCONFIGENVOVERRIDE_NAME_ENV = "TESTCONFIGENVOVERRIDE_NAME"
+)
+
+var (
+	ErrDatabaseUrlEnvMissing               = errors.New(DATABASE_URL_ENV)
+	ErrPortEnvInvalid                      = errors.New(PORT_ENV)
+	ErrTestconfigenvoverrideNameEnvMissing = errors.New(TESTCONFIGENVOVERRIDE_NAME_ENV)
+)
+
+// LoadTestConfigEnvOverride reads the config from the environment.
+func LoadTestConfigEnvOverride(opts ...LoadOption) (TestConfigEnvOverride, error) {
+	return LoadTestConfigEnvOverrideFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigEnvOverrideFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigEnvOverrideFromMap(m map[string]string, opts ...LoadOption) (TestConfigEnvOverride, error) {
+	return LoadTestConfigEnvOverrideFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigEnvOverrideFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigEnvOverrideFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigEnvOverride, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	sources := []func(string) (string, bool){lookup}
+	if len(options.dotenvFiles) > 0 {
+		values, err := readDotenvFiles(options.dotenvFiles, false)
+		if err != nil {
+			return TestConfigEnvOverride{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+	lookup = layerLookups(nil, sources...)
+
+	resolver := options.secretResolver
+	if resolver == nil {
+		resolver = FileSecretResolver{}
+	}
+	timeout := options.secretTimeout
+	if timeout <= 0 {
+		timeout = defaultSecretTimeout
+	}
+	values, secrets, err := resolveSecrets(resolver, timeout, lookup, []string{
+		DATABASE_URL_ENV,
+		PORT_ENV,
+		TESTCONFIGENVOVERRIDE_NAME_ENV,
+	})
+	if err != nil {
+		return TestConfigEnvOverride{}, err
+	}
+	lookup = func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+
+	var config TestConfigEnvOverride
+	var missingVars []error
+	var formatVars []error
+	val_DatabaseURL, ok := lookup(DATABASE_URL_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "DatabaseURL", EnvVar: DATABASE_URL_ENV, Kind: "missing", sentinel: ErrDatabaseUrlEnvMissing})
+	} else {
+		config.DatabaseURL = val_DatabaseURL
+	}
+	val_Port, ok := lookup(PORT_ENV)
+	if !ok {
+		val_Port = "8080"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Port", PORT_ENV, ErrPortEnvInvalid, val_Port, secrets[PORT_ENV], err))
+		} else {
+			config.Port = parsed
+		}
+	}
+	val_Name, ok := lookup(TESTCONFIGENVOVERRIDE_NAME_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Name", EnvVar: TESTCONFIGENVOVERRIDE_NAME_ENV, Kind: "missing", sentinel: ErrTestconfigenvoverrideNameEnvMissing})
+	} else {
+		config.Name = val_Name
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		return TestConfigEnvOverride{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning   func(Warning)
+	dotenvFiles []string
+
+	secretResolver SecretResolver
+	secretTimeout  time.Duration
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+// WithDotenv layers the values of dotenv files under the real environment.
+// Later files take precedence over earlier ones, and files that do not
+// exist are skipped, e.g. WithDotenv(".env", ".env.local").
+func WithDotenv(paths ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenvFiles = append(o.dotenvFiles, paths...)
+	}
+}
+
+// WithSecretResolver sets the resolver used for values that are secret
+// references, e.g. secretref://payments/db-password. It defaults to
+// FileSecretResolver{}.
+func WithSecretResolver(r SecretResolver) LoadOption {
+	return func(o *loadOptions) {
+		o.secretResolver = r
+	}
+}
+
+// WithSecretTimeout bounds the time spent resolving all secret references,
+// which are resolved concurrently. It defaults to 10 seconds.
+func WithSecretTimeout(d time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.secretTimeout = d
+	}
+}
+
+const (
+	secretRefScheme      = "secretref://"
+	defaultSecretTimeout = 10 * time.Second
+)
+
+// SecretResolver resolves a secret reference, such as
+// secretref://payments/db-password, into the secret it points to.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, ref string) (string, error)
+}
+
+// FileSecretResolver resolves secretref://path by reading the file at path,
+// relative to Dir if it is set, and trimming trailing newlines. With Dir
+// set, paths that escape it are rejected.
+type FileSecretResolver struct {
+	Dir string
+}
+
+func (r FileSecretResolver) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	path, ok := strings.CutPrefix(ref, secretRefScheme)
+	if !ok || path == "" {
+		return "", errors.New("invalid secret reference " + ref)
+	}
+	if r.Dir != "" {
+		if !filepath.IsLocal(path) {
+			return "", errors.New("secret reference " + ref + " is outside of " + r.Dir)
+		}
+		path = filepath.Join(r.Dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// UnresolvedSecretsError lists the env vars whose secret references could
+// not be resolved. Each of them unwraps to the error of the resolver.
+type UnresolvedSecretsError struct {
+	vars []error
+}
+
+func (m UnresolvedSecretsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m UnresolvedSecretsError) Error() string {
+	return joinEnvVars("secrets of envs ", m.vars, " could not be resolved")
+}
+
+type secretError struct {
+	envVar string
+	ref    string
+	err    error
+}
+
+func (e secretError) Error() string {
+	return e.envVar + " (" + e.ref + ": " + e.err.Error() + ")"
+}
+
+func (e secretError) Unwrap() error {
+	return e.err
+}
+
+// layerLookups combines sources, earlier ones taking precedence. The names of
+// each group in aliases belong to one field and are all read from the first
+// source that sets any of them, so that a source wins outright instead of
+// mixing its value with the aliases set in the sources below it.
+func layerLookups(aliases [][]string, sources ...func(string) (string, bool)) func(string) (string, bool) {
+	groups := map[string][]string{}
+	for _, names := range aliases {
+		for _, name := range names {
+			groups[name] = names
+		}
+	}
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			if value, ok := source(name); ok {
+				return value, true
+			}
+			for _, alias := range groups[name] {
+				if alias == name {
+					continue
+				}
+				if _, ok := source(alias); ok {
+					return "", false
+				}
+			}
+		}
+		return "", false
+	}
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// readDotenvFiles reads and merges dotenv files, later files taking
+// precedence. Files that do not exist are skipped. escapeDollar is passed
+// on to parseDotenv.
+func readDotenvFiles(paths []string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := parseDotenv(path, string(data), escapeDollar)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// parseDotenv parses the contents of a dotenv file. It supports comments,
+// an optional export prefix, single quoted values (taken literally), double
+// quoted values (with \n, \r, \t, \", \\ and \$ escapes, possibly spanning
+// several lines) and unquoted values, which end at an inline " #" comment.
+// With escapeDollar, the literal dollars of \$ escapes and single quoted
+// values are written as $$, so that interpolation keeps them as they are.
+func parseDotenv(path string, data string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	line := 1
+	fail := func(msg string) error {
+		return errors.New(path + ":" + strconv.Itoa(line) + ": " + msg)
+	}
+	lineEnd := func(i int) int {
+		if end := strings.IndexByte(data[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(data)
+	}
+	i := 0
+	for i < len(data) {
+		switch data[i] {
+		case '\n':
+			line++
+			i++
+			continue
+		case ' ', '\t', '\r':
+			i++
+			continue
+		case '#':
+			i = lineEnd(i)
+			continue
+		}
+
+		end := lineEnd(i)
+		if rest, ok := strings.CutPrefix(data[i:end], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			i += len("export")
+		}
+		eq := strings.IndexByte(data[i:end], '=')
+		if eq < 0 {
+			return nil, fail("expected KEY=value")
+		}
+		key := strings.TrimSpace(data[i : i+eq])
+		if key == "" || strings.ContainsAny(key, " \t'\"#") {
+			return nil, fail("invalid key " + strconv.Quote(key))
+		}
+		i += eq + 1
+		for i < end && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+
+		if i < end && (data[i] == '\'' || data[i] == '"') {
+			quote := data[i]
+			i++
+			startLine := line
+			var sb strings.Builder
+			closed := false
+			for i < len(data) {
+				c := data[i]
+				if c == quote {
+					closed = true
+					i++
+					break
+				}
+				if quote == '"' && c == '\\' && i+1 < len(data) {
+					switch next := data[i+1]; next {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					case '$':
+						if escapeDollar {
+							sb.WriteByte('$')
+						}
+						sb.WriteByte('$')
+					case '"', '\\':
+						sb.WriteByte(next)
+					default:
+						sb.WriteByte(c)
+						sb.WriteByte(next)
+						if next == '\n' {
+							line++
+						}
+					}
+					i += 2
+					continue
+				}
+				if c == '\n' {
+					line++
+				}
+				if quote == '\'' && c == '$' && escapeDollar {
+					sb.WriteByte('$')
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				line = startLine
+				return nil, fail("unterminated quoted value for " + key)
+			}
+			end = lineEnd(i)
+			if rest := strings.TrimSpace(data[i:end]); rest != "" && rest[0] != '#' {
+				return nil, fail("unexpected characters after quoted value for " + key)
+			}
+			values[key] = sb.String()
+		} else {
+			raw := data[i:end]
+			if comment := strings.Index(raw, " #"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			if comment := strings.Index(raw, "\t#"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			values[key] = strings.TrimSpace(raw)
+		}
+		i = end
+	}
+	return values, nil
+}
+
+// resolveSecrets looks up names once and returns the values of those that
+// are set, with secret references resolved concurrently and within timeout,
+// and the names whose values were secret references.
+// Resolvers that ignore the context are abandoned once the timeout expires.
+func resolveSecrets(resolver SecretResolver, timeout time.Duration, lookup func(string) (string, bool), names []string) (map[string]string, map[string]bool, error) {
+	values := map[string]string{}
+	secrets := map[string]bool{}
+	var pending []string
+	for _, name := range names {
+		if _, seen := values[name]; seen {
+			continue
+		}
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		values[name] = value
+		if strings.HasPrefix(value, secretRefScheme) {
+			pending = append(pending, name)
+			secrets[name] = true
+		}
+	}
+	if len(pending) == 0 {
+		return values, secrets, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	type result struct {
+		value string
+		err   error
+	}
+	results := make([]chan result, len(pending))
+	for i, name := range pending {
+		results[i] = make(chan result, 1)
+		go func(ch chan<- result, ref string) {
+			value, err := resolver.ResolveSecret(ctx, ref)
+			ch <- result{value: value, err: err}
+		}(results[i], values[name])
+	}
+
+	var errs []error
+	for i, name := range pending {
+		var r result
+		select {
+		case r = <-results[i]:
+		case <-ctx.Done():
+			select {
+			case r = <-results[i]:
+			default:
+				r.err = ctx.Err()
+			}
+		}
+		if r.err != nil {
+			errs = append(errs, secretError{envVar: name, ref: values[name], err: r.err})
+		} else {
+			values[name] = r.value
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, UnresolvedSecretsError{vars: errs}
+	}
+	return values, secrets, nil
+}
@@ -0,0 +1,19 @@
+//go:build testcases
+// +build testcases
+
+package t11
+
+type Postgres struct {
+	Host string
+	Port int
+}
+
+type Logging struct {
+	Level string
+}
+
+type TestConfigEnvPrefix struct {
+	Database Postgres `envprefix:"PG"`
+	Logging  Logging  `envprefix:""`
+	Replica  Postgres
+}
@@ -0,0 +1,233 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t11
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	TESTCONFIGENVPREFIX_PG_HOST_ENV      = "TESTCONFIGENVPREFIX_PG_HOST"
+	TESTCONFIGENVPREFIX_PG_PORT_ENV      = "TESTCONFIGENVPREFIX_PG_PORT"
+	TESTCONFIGENVPREFIX_LEVEL_ENV        = "TESTCONFIGENVPREFIX_LEVEL"
+	TESTCONFIGENVPREFIX_REPLICA_HOST_ENV = "TESTCONFIGENVPREFIX_REPLICA_HOST"
+	TESTCONFIGENVPREFIX_REPLICA_PORT_ENV = "TESTCONFIGENVPREFIX_REPLICA_PORT"
+)
+
+var (
+	ErrTestconfigenvprefixPgHostEnvMissing      = errors.New(TESTCONFIGENVPREFIX_PG_HOST_ENV)
+	ErrTestconfigenvprefixPgPortEnvMissing      = errors.New(TESTCONFIGENVPREFIX_PG_PORT_ENV)
+	ErrTestconfigenvprefixPgPortEnvInvalid      = errors.New(TESTCONFIGENVPREFIX_PG_PORT_ENV)
+	ErrTestconfigenvprefixLevelEnvMissing       = errors.New(TESTCONFIGENVPREFIX_LEVEL_ENV)
+	ErrTestconfigenvprefixReplicaHostEnvMissing = errors.New(TESTCONFIGENVPREFIX_REPLICA_HOST_ENV)
+	ErrTestconfigenvprefixReplicaPortEnvMissing = errors.New(TESTCONFIGENVPREFIX_REPLICA_PORT_ENV)
+	ErrTestconfigenvprefixReplicaPortEnvInvalid = errors.New(TESTCONFIGENVPREFIX_REPLICA_PORT_ENV)
+)
+
+// LoadTestConfigEnvPrefix reads the config from the environment.
+func LoadTestConfigEnvPrefix(opts ...LoadOption) (TestConfigEnvPrefix, error) {
+	return LoadTestConfigEnvPrefixFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigEnvPrefixFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigEnvPrefixFromMap(m map[string]string, opts ...LoadOption) (TestConfigEnvPrefix, error) {
+	return LoadTestConfigEnvPrefixFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigEnvPrefixFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigEnvPrefixFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigEnvPrefix, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigEnvPrefix
+	var missingVars []error
+	var formatVars []error
+	val_Database_Host, ok := lookup(TESTCONFIGENVPREFIX_PG_HOST_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Database.Host", EnvVar: TESTCONFIGENVPREFIX_PG_HOST_ENV, Kind: "missing", sentinel: ErrTestconfigenvprefixPgHostEnvMissing})
+	} else {
+		config.Database.Host = val_Database_Host
+	}
+	val_Database_Port, ok := lookup(TESTCONFIGENVPREFIX_PG_PORT_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Database.Port", EnvVar: TESTCONFIGENVPREFIX_PG_PORT_ENV, Kind: "missing", sentinel: ErrTestconfigenvprefixPgPortEnvMissing})
+	} else {
+		parsed, err := strconv.Atoi(val_Database_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Database.Port", TESTCONFIGENVPREFIX_PG_PORT_ENV, ErrTestconfigenvprefixPgPortEnvInvalid, val_Database_Port, false, err))
+		} else {
+			config.Database.Port = parsed
+		}
+	}
+	val_Logging_Level, ok := lookup(TESTCONFIGENVPREFIX_LEVEL_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Logging.Level", EnvVar: TESTCONFIGENVPREFIX_LEVEL_ENV, Kind: "missing", sentinel: ErrTestconfigenvprefixLevelEnvMissing})
+	} else {
+		config.Logging.Level = val_Logging_Level
+	}
+	val_Replica_Host, ok := lookup(TESTCONFIGENVPREFIX_REPLICA_HOST_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Replica.Host", EnvVar: TESTCONFIGENVPREFIX_REPLICA_HOST_ENV, Kind: "missing", sentinel: ErrTestconfigenvprefixReplicaHostEnvMissing})
+	} else {
+		config.Replica.Host = val_Replica_Host
+	}
+	val_Replica_Port, ok := lookup(TESTCONFIGENVPREFIX_REPLICA_PORT_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Replica.Port", EnvVar: TESTCONFIGENVPREFIX_REPLICA_PORT_ENV, Kind: "missing", sentinel: ErrTestconfigenvprefixReplicaPortEnvMissing})
+	} else {
+		parsed, err := strconv.Atoi(val_Replica_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Replica.Port", TESTCONFIGENVPREFIX_REPLICA_PORT_ENV, ErrTestconfigenvprefixReplicaPortEnvInvalid, val_Replica_Port, false, err))
+		} else {
+			config.Replica.Port = parsed
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		return TestConfigEnvPrefix{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
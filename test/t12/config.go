@@ -0,0 +1,19 @@
+//go:build testcases
+// +build testcases
+
+package t12
+
+import "time"
+
+type HTTPServer struct {
+	HTTPPort         int
+	ShutdownInterval time.Duration
+}
+
+type TestConfigSnake struct {
+	APIKey      string
+	Int8Val     int8
+	S3Bucket    string
+	DatabaseURL string `env:"DATABASE_URL"`
+	Server      HTTPServer
+}
@@ -0,0 +1,248 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t12
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TEST_CONFIG_SNAKE_API_KEY_ENV                  = "TEST_CONFIG_SNAKE_API_KEY"
+	TEST_CONFIG_SNAKE_INT8_VAL_ENV                 = "TEST_CONFIG_SNAKE_INT8_VAL"
+	TEST_CONFIG_SNAKE_S3_BUCKET_ENV                = "TEST_CONFIG_SNAKE_S3_BUCKET"
+	DATABASE_URL_ENV                               = "DATABASE_URL"
+	TEST_CONFIG_SNAKE_SERVER_HTTP_PORT_ENV         = "TEST_CONFIG_SNAKE_SERVER_HTTP_PORT"
+	TEST_CONFIG_SNAKE_SERVER_SHUTDOWN_INTERVAL_ENV = "TEST_CONFIG_SNAKE_SERVER_SHUTDOWN_INTERVAL"
+)
+
+var (
+	ErrTestConfigSnakeApiKeyEnvMissing                 = errors.New(TEST_CONFIG_SNAKE_API_KEY_ENV)
+	ErrTestConfigSnakeInt8ValEnvMissing                = errors.New(TEST_CONFIG_SNAKE_INT8_VAL_ENV)
+	ErrTestConfigSnakeInt8ValEnvInvalid                = errors.New(TEST_CONFIG_SNAKE_INT8_VAL_ENV)
+	ErrTestConfigSnakeS3BucketEnvMissing               = errors.New(TEST_CONFIG_SNAKE_S3_BUCKET_ENV)
+	ErrDatabaseUrlEnvMissing                           = errors.New(DATABASE_URL_ENV)
+	ErrTestConfigSnakeServerHttpPortEnvMissing         = errors.New(TEST_CONFIG_SNAKE_SERVER_HTTP_PORT_ENV)
+	ErrTestConfigSnakeServerHttpPortEnvInvalid         = errors.New(TEST_CONFIG_SNAKE_SERVER_HTTP_PORT_ENV)
+	ErrTestConfigSnakeServerShutdownIntervalEnvMissing = errors.New(TEST_CONFIG_SNAKE_SERVER_SHUTDOWN_INTERVAL_ENV)
+	ErrTestConfigSnakeServerShutdownIntervalEnvInvalid = errors.New(TEST_CONFIG_SNAKE_SERVER_SHUTDOWN_INTERVAL_ENV)
+)
+
+// LoadTestConfigSnake reads the config from the environment.
+func LoadTestConfigSnake(opts ...LoadOption) (TestConfigSnake, error) {
+	return LoadTestConfigSnakeFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigSnakeFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigSnakeFromMap(m map[string]string, opts ...LoadOption) (TestConfigSnake, error) {
+	return LoadTestConfigSnakeFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigSnakeFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigSnakeFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigSnake, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigSnake
+	var missingVars []error
+	var formatVars []error
+	val_APIKey, ok := lookup(TEST_CONFIG_SNAKE_API_KEY_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "APIKey", EnvVar: TEST_CONFIG_SNAKE_API_KEY_ENV, Kind: "missing", sentinel: ErrTestConfigSnakeApiKeyEnvMissing})
+	} else {
+		config.APIKey = val_APIKey
+	}
+	val_Int8Val, ok := lookup(TEST_CONFIG_SNAKE_INT8_VAL_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Int8Val", EnvVar: TEST_CONFIG_SNAKE_INT8_VAL_ENV, Kind: "missing", sentinel: ErrTestConfigSnakeInt8ValEnvMissing})
+	} else {
+		parsed, err := strconv.ParseInt(val_Int8Val, 10, 8)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Int8Val", TEST_CONFIG_SNAKE_INT8_VAL_ENV, ErrTestConfigSnakeInt8ValEnvInvalid, val_Int8Val, false, err))
+		} else {
+			config.Int8Val = int8(parsed)
+		}
+	}
+	val_S3Bucket, ok := lookup(TEST_CONFIG_SNAKE_S3_BUCKET_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "S3Bucket", EnvVar: TEST_CONFIG_SNAKE_S3_BUCKET_ENV, Kind: "missing", sentinel: ErrTestConfigSnakeS3BucketEnvMissing})
+	} else {
+		config.S3Bucket = val_S3Bucket
+	}
+	val_DatabaseURL, ok := lookup(DATABASE_URL_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "DatabaseURL", EnvVar: DATABASE_URL_ENV, Kind: "missing", sentinel: ErrDatabaseUrlEnvMissing})
+	} else {
+		config.DatabaseURL = val_DatabaseURL
+	}
+	val_Server_HTTPPort, ok := lookup(TEST_CONFIG_SNAKE_SERVER_HTTP_PORT_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Server.HTTPPort", EnvVar: TEST_CONFIG_SNAKE_SERVER_HTTP_PORT_ENV, Kind: "missing", sentinel: ErrTestConfigSnakeServerHttpPortEnvMissing})
+	} else {
+		parsed, err := strconv.Atoi(val_Server_HTTPPort)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Server.HTTPPort", TEST_CONFIG_SNAKE_SERVER_HTTP_PORT_ENV, ErrTestConfigSnakeServerHttpPortEnvInvalid, val_Server_HTTPPort, false, err))
+		} else {
+			config.Server.HTTPPort = parsed
+		}
+	}
+	val_Server_ShutdownInterval, ok := lookup(TEST_CONFIG_SNAKE_SERVER_SHUTDOWN_INTERVAL_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Server.ShutdownInterval", EnvVar: TEST_CONFIG_SNAKE_SERVER_SHUTDOWN_INTERVAL_ENV, Kind: "missing", sentinel: ErrTestConfigSnakeServerShutdownIntervalEnvMissing})
+	} else {
+		parsed, err := time.ParseDuration(val_Server_ShutdownInterval)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Server.ShutdownInterval", TEST_CONFIG_SNAKE_SERVER_SHUTDOWN_INTERVAL_ENV, ErrTestConfigSnakeServerShutdownIntervalEnvInvalid, val_Server_ShutdownInterval, false, err))
+		} else {
+			config.Server.ShutdownInterval = parsed
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		return TestConfigSnake{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
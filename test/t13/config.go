@@ -0,0 +1,13 @@
+//go:build testcases
+// +build testcases
+
+package t13
+
+type Server struct {
+	HTTPPort int
+}
+
+type TestConfigKebab struct {
+	LogLevel string
+	Server   Server
+}
@@ -0,0 +1,203 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t13
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	TEST_CONFIG_KEBAB_LOG_LEVEL_ENV        = "test-config-kebab-log-level"
+	TEST_CONFIG_KEBAB_SERVER_HTTP_PORT_ENV = "test-config-kebab-server-http-port"
+)
+
+var (
+	ErrTestConfigKebabLogLevelEnvMissing       = errors.New(TEST_CONFIG_KEBAB_LOG_LEVEL_ENV)
+	ErrTestConfigKebabServerHttpPortEnvMissing = errors.New(TEST_CONFIG_KEBAB_SERVER_HTTP_PORT_ENV)
+	ErrTestConfigKebabServerHttpPortEnvInvalid = errors.New(TEST_CONFIG_KEBAB_SERVER_HTTP_PORT_ENV)
+)
+
+// LoadTestConfigKebab reads the config from the environment.
+func LoadTestConfigKebab(opts ...LoadOption) (TestConfigKebab, error) {
+	return LoadTestConfigKebabFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigKebabFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigKebabFromMap(m map[string]string, opts ...LoadOption) (TestConfigKebab, error) {
+	return LoadTestConfigKebabFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigKebabFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigKebabFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigKebab, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigKebab
+	var missingVars []error
+	var formatVars []error
+	val_LogLevel, ok := lookup(TEST_CONFIG_KEBAB_LOG_LEVEL_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "LogLevel", EnvVar: TEST_CONFIG_KEBAB_LOG_LEVEL_ENV, Kind: "missing", sentinel: ErrTestConfigKebabLogLevelEnvMissing})
+	} else {
+		config.LogLevel = val_LogLevel
+	}
+	val_Server_HTTPPort, ok := lookup(TEST_CONFIG_KEBAB_SERVER_HTTP_PORT_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Server.HTTPPort", EnvVar: TEST_CONFIG_KEBAB_SERVER_HTTP_PORT_ENV, Kind: "missing", sentinel: ErrTestConfigKebabServerHttpPortEnvMissing})
+	} else {
+		parsed, err := strconv.Atoi(val_Server_HTTPPort)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Server.HTTPPort", TEST_CONFIG_KEBAB_SERVER_HTTP_PORT_ENV, ErrTestConfigKebabServerHttpPortEnvInvalid, val_Server_HTTPPort, false, err))
+		} else {
+			config.Server.HTTPPort = parsed
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		return TestConfigKebab{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
@@ -0,0 +1,9 @@
+//go:build testcases
+// +build testcases
+
+package t14
+
+type TestConfigAliases struct {
+	DatabaseURL string `env:"APP_DB_URL" aliases:"DATABASE_URL,APP_DATABASE"`
+	Port        int    `aliases:"PORT" default:"8080"`
+}
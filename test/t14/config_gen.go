@@ -0,0 +1,567 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t14
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	APP_DB_URL_ENV             = "APP_DB_URL"
+	TESTCONFIGALIASES_PORT_ENV = "TESTCONFIGALIASES_PORT"
+)
+
+var (
+	ErrAppDbUrlEnvMissing               = errors.New(APP_DB_URL_ENV)
+	ErrAppDbUrlEnvConflict              = errors.New(APP_DB_URL_ENV)
+	ErrTestconfigaliasesPortEnvInvalid  = errors.New(TESTCONFIGALIASES_PORT_ENV)
+	ErrTestconfigaliasesPortEnvConflict = errors.New(TESTCONFIGALIASES_PORT_ENV)
+)
+
+// LoadTestConfigAliases reads the config from the environment.
+func LoadTestConfigAliases(opts ...LoadOption) (TestConfigAliases, error) {
+	return LoadTestConfigAliasesFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigAliasesWithFlags reads the config from the flags set on fs,
+// falling back to the environment and then to the defaults. The flags must
+// have been registered with RegisterTestConfigAliasesFlags and fs must be parsed.
+func LoadTestConfigAliasesWithFlags(fs *flag.FlagSet, opts ...LoadOption) (TestConfigAliases, error) {
+	return LoadTestConfigAliases(append(opts, WithFlags(fs))...)
+}
+
+// RegisterTestConfigAliasesFlags defines a flag on fs for every field of
+// TestConfigAliases, named after the lowercased field path, e.g. -server.port.
+// Values are parsed when the config is loaded, like env vars.
+func RegisterTestConfigAliasesFlags(fs *flag.FlagSet) {
+	fs.Var(&flagValue{envVar: APP_DB_URL_ENV}, "databaseurl", "env APP_DB_URL")
+	fs.Var(&flagValue{envVar: TESTCONFIGALIASES_PORT_ENV, value: "8080"}, "port", "env TESTCONFIGALIASES_PORT")
+}
+
+// LoadTestConfigAliasesFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigAliasesFromMap(m map[string]string, opts ...LoadOption) (TestConfigAliases, error) {
+	return LoadTestConfigAliasesFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigAliasesFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigAliasesFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigAliases, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	sources := []func(string) (string, bool){lookup}
+	if options.configDir != "" {
+		values, err := readConfigDir(options.configDir, []string{
+			APP_DB_URL_ENV, "DATABASE_URL", "APP_DATABASE",
+			TESTCONFIGALIASES_PORT_ENV, "PORT",
+		})
+		if err != nil {
+			return TestConfigAliases{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+	if len(options.dotenvFiles) > 0 {
+		values, err := readDotenvFiles(options.dotenvFiles, false)
+		if err != nil {
+			return TestConfigAliases{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+
+	if options.flagSet != nil {
+		flagValues := map[string]string{}
+		options.flagSet.Visit(func(f *flag.Flag) {
+			if v, ok := f.Value.(*flagValue); ok {
+				flagValues[v.envVar] = v.value
+			}
+		})
+		sources = append([]func(string) (string, bool){func(name string) (string, bool) {
+			value, ok := flagValues[name]
+			return value, ok
+		}}, sources...)
+	}
+	lookup = layerLookups([][]string{
+		{APP_DB_URL_ENV, "DATABASE_URL", "APP_DATABASE"},
+		{TESTCONFIGALIASES_PORT_ENV, "PORT"},
+	}, sources...)
+
+	var config TestConfigAliases
+	var missingVars []error
+	var formatVars []error
+	var conflictVars []error
+	val_DatabaseURL, from, ok, conflict := lookupEnvWithAliases(lookup, nil, APP_DB_URL_ENV, "DATABASE_URL", "APP_DATABASE")
+	if conflict {
+		conflictVars = append(conflictVars, ErrAppDbUrlEnvConflict)
+	} else if ok && from != APP_DB_URL_ENV {
+		options.warn(Warning{EnvVar: APP_DB_URL_ENV, Alias: from})
+	}
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "DatabaseURL", EnvVar: APP_DB_URL_ENV, Kind: "missing", sentinel: ErrAppDbUrlEnvMissing})
+	} else {
+		config.DatabaseURL = val_DatabaseURL
+	}
+	val_Port, from, ok, conflict := lookupEnvWithAliases(lookup, nil, TESTCONFIGALIASES_PORT_ENV, "PORT")
+	if conflict {
+		conflictVars = append(conflictVars, ErrTestconfigaliasesPortEnvConflict)
+	} else if ok && from != TESTCONFIGALIASES_PORT_ENV {
+		options.warn(Warning{EnvVar: TESTCONFIGALIASES_PORT_ENV, Alias: from})
+	}
+	if !ok {
+		val_Port = "8080"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Port", TESTCONFIGALIASES_PORT_ENV, ErrTestconfigaliasesPortEnvInvalid, val_Port, false, err))
+		} else {
+			config.Port = parsed
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(conflictVars) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(conflictVars) > 0 {
+			verr = errors.Join(verr, ConflictingEnvVarsError{vars: conflictVars})
+		}
+		return TestConfigAliases{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning   func(Warning)
+	dotenvFiles []string
+	configDir   string
+	flagSet     *flag.FlagSet
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+// WithDotenv layers the values of dotenv files under the real environment.
+// Later files take precedence over earlier ones, and files that do not
+// exist are skipped, e.g. WithDotenv(".env", ".env.local").
+func WithDotenv(paths ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenvFiles = append(o.dotenvFiles, paths...)
+	}
+}
+
+// WithConfigDir layers the files of a directory, such as a mounted
+// ConfigMap or Secret, under the real environment. Each file is named after
+// an env var, in its exact, lowercased or dotted form (e.g. app.db.url),
+// and takes precedence over dotenv and JSON config files. The directory is
+// read on every load, so updates to the mount are picked up.
+func WithConfigDir(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.configDir = path
+	}
+}
+
+// WithFlags gives the flags set on fs precedence over every other source.
+// fs must be parsed and its flags registered with the Register...Flags
+// function of the config.
+func WithFlags(fs *flag.FlagSet) LoadOption {
+	return func(o *loadOptions) {
+		o.flagSet = fs
+	}
+}
+
+// flagValue holds the raw value of a flag, which is parsed together with
+// the other sources of envVar.
+type flagValue struct {
+	envVar string
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+type ConflictingEnvVarsError struct {
+	vars []error
+}
+
+func (m ConflictingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ConflictingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are set to different values through their aliases")
+}
+
+// layerLookups combines sources, earlier ones taking precedence. The names of
+// each group in aliases belong to one field and are all read from the first
+// source that sets any of them, so that a source wins outright instead of
+// mixing its value with the aliases set in the sources below it.
+func layerLookups(aliases [][]string, sources ...func(string) (string, bool)) func(string) (string, bool) {
+	groups := map[string][]string{}
+	for _, names := range aliases {
+		for _, name := range names {
+			groups[name] = names
+		}
+	}
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			if value, ok := source(name); ok {
+				return value, true
+			}
+			for _, alias := range groups[name] {
+				if alias == name {
+					continue
+				}
+				if _, ok := source(alias); ok {
+					return "", false
+				}
+			}
+		}
+		return "", false
+	}
+}
+
+// lookupEnvWithAliases returns the value of the first of names that is set
+// and the name it was read from. It also reports whether two of the names
+// are set to different values. Unless unset is nil, the values it reports
+// true for are skipped as if the name was not set.
+func lookupEnvWithAliases(lookup func(string) (string, bool), unset func(string) bool, names ...string) (value string, from string, ok bool, conflict bool) {
+	for _, name := range names {
+		v, set := lookup(name)
+		if !set || unset != nil && unset(v) {
+			continue
+		}
+		if !ok {
+			value, from, ok = v, name, true
+		} else if v != value {
+			conflict = true
+		}
+	}
+	return value, from, ok, conflict
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// readConfigDir reads a directory with one file per variable, such as a
+// mounted ConfigMap or Secret. A variable is read from the file named after
+// it, its lowercased form or its lowercased form with dots as separators,
+// e.g. APP_DB_URL, app_db_url or app.db.url. Trailing newlines are trimmed,
+// and a directory that does not exist is skipped.
+func readConfigDir(dir string, names []string) (map[string]string, error) {
+	values := map[string]string{}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		dotted := strings.NewReplacer("_", ".", "-", ".").Replace(lower)
+		for _, file := range []string{name, lower, dotted} {
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[name] = strings.TrimRight(string(data), "\r\n")
+			break
+		}
+	}
+	return values, nil
+}
+
+// readDotenvFiles reads and merges dotenv files, later files taking
+// precedence. Files that do not exist are skipped. escapeDollar is passed
+// on to parseDotenv.
+func readDotenvFiles(paths []string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := parseDotenv(path, string(data), escapeDollar)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// parseDotenv parses the contents of a dotenv file. It supports comments,
+// an optional export prefix, single quoted values (taken literally), double
+// quoted values (with \n, \r, \t, \", \\ and \$ escapes, possibly spanning
+// several lines) and unquoted values, which end at an inline " #" comment.
+// With escapeDollar, the literal dollars of \$ escapes and single quoted
+// values are written as $$, so that interpolation keeps them as they are.
+func parseDotenv(path string, data string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	line := 1
+	fail := func(msg string) error {
+		return errors.New(path + ":" + strconv.Itoa(line) + ": " + msg)
+	}
+	lineEnd := func(i int) int {
+		if end := strings.IndexByte(data[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(data)
+	}
+	i := 0
+	for i < len(data) {
+		switch data[i] {
+		case '\n':
+			line++
+			i++
+			continue
+		case ' ', '\t', '\r':
+			i++
+			continue
+		case '#':
+			i = lineEnd(i)
+			continue
+		}
+
+		end := lineEnd(i)
+		if rest, ok := strings.CutPrefix(data[i:end], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			i += len("export")
+		}
+		eq := strings.IndexByte(data[i:end], '=')
+		if eq < 0 {
+			return nil, fail("expected KEY=value")
+		}
+		key := strings.TrimSpace(data[i : i+eq])
+		if key == "" || strings.ContainsAny(key, " \t'\"#") {
+			return nil, fail("invalid key " + strconv.Quote(key))
+		}
+		i += eq + 1
+		for i < end && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+
+		if i < end && (data[i] == '\'' || data[i] == '"') {
+			quote := data[i]
+			i++
+			startLine := line
+			var sb strings.Builder
+			closed := false
+			for i < len(data) {
+				c := data[i]
+				if c == quote {
+					closed = true
+					i++
+					break
+				}
+				if quote == '"' && c == '\\' && i+1 < len(data) {
+					switch next := data[i+1]; next {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					case '$':
+						if escapeDollar {
+							sb.WriteByte('$')
+						}
+						sb.WriteByte('$')
+					case '"', '\\':
+						sb.WriteByte(next)
+					default:
+						sb.WriteByte(c)
+						sb.WriteByte(next)
+						if next == '\n' {
+							line++
+						}
+					}
+					i += 2
+					continue
+				}
+				if c == '\n' {
+					line++
+				}
+				if quote == '\'' && c == '$' && escapeDollar {
+					sb.WriteByte('$')
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				line = startLine
+				return nil, fail("unterminated quoted value for " + key)
+			}
+			end = lineEnd(i)
+			if rest := strings.TrimSpace(data[i:end]); rest != "" && rest[0] != '#' {
+				return nil, fail("unexpected characters after quoted value for " + key)
+			}
+			values[key] = sb.String()
+		} else {
+			raw := data[i:end]
+			if comment := strings.Index(raw, " #"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			if comment := strings.Index(raw, "\t#"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			values[key] = strings.TrimSpace(raw)
+		}
+		i = end
+	}
+	return values, nil
+}
@@ -0,0 +1,13 @@
+//go:build testcases
+// +build testcases
+
+package t15
+
+type Server struct {
+	HTTPPort int
+}
+
+type TestConfigSeparator struct {
+	LogLevel string
+	Server   Server
+}
@@ -0,0 +1,9 @@
+//go:build testcases
+// +build testcases
+
+package t16
+
+type TestConfigCaseInsensitive struct {
+	LogLevel string
+	Port     int `aliases:"PORT"`
+}
@@ -0,0 +1,343 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t16
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	TESTCONFIGCI_LOGLEVEL_ENV = "TESTCONFIGCI_LOGLEVEL"
+	TESTCONFIGCI_PORT_ENV     = "TESTCONFIGCI_PORT"
+)
+
+var (
+	ErrTestconfigciLoglevelEnvMissing   = errors.New(TESTCONFIGCI_LOGLEVEL_ENV)
+	ErrTestconfigciLoglevelEnvAmbiguous = errors.New(TESTCONFIGCI_LOGLEVEL_ENV)
+	ErrTestconfigciPortEnvMissing       = errors.New(TESTCONFIGCI_PORT_ENV)
+	ErrTestconfigciPortEnvInvalid       = errors.New(TESTCONFIGCI_PORT_ENV)
+	ErrTestconfigciPortEnvConflict      = errors.New(TESTCONFIGCI_PORT_ENV)
+	ErrTestconfigciPortEnvAmbiguous     = errors.New(TESTCONFIGCI_PORT_ENV)
+)
+
+// LoadTestConfigCaseInsensitive reads the config from the environment.
+func LoadTestConfigCaseInsensitive(opts ...LoadOption) (TestConfigCaseInsensitive, error) {
+	opts = append([]LoadOption{withEnvIndex(newEnvIndex(os.Environ()))}, opts...)
+	return LoadTestConfigCaseInsensitiveFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigCaseInsensitiveFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigCaseInsensitiveFromMap(m map[string]string, opts ...LoadOption) (TestConfigCaseInsensitive, error) {
+	opts = append([]LoadOption{withEnvIndex(newEnvIndexFromMap(m))}, opts...)
+	return LoadTestConfigCaseInsensitiveFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigCaseInsensitiveFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+//
+// Since lookup cannot be enumerated, names are passed to it as they are and
+// it is responsible for matching them regardless of case.
+func LoadTestConfigCaseInsensitiveFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigCaseInsensitive, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.envIndex != nil {
+		lookup = options.envIndex.lookup
+	}
+
+	var config TestConfigCaseInsensitive
+	var missingVars []error
+	var formatVars []error
+	var conflictVars []error
+	var ambiguousVars []error
+	if options.envIndex.ambiguous(TESTCONFIGCI_LOGLEVEL_ENV) {
+		ambiguousVars = append(ambiguousVars, ErrTestconfigciLoglevelEnvAmbiguous)
+	}
+	val_LogLevel, ok := lookup(TESTCONFIGCI_LOGLEVEL_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "LogLevel", EnvVar: TESTCONFIGCI_LOGLEVEL_ENV, Kind: "missing", sentinel: ErrTestconfigciLoglevelEnvMissing})
+	} else {
+		config.LogLevel = val_LogLevel
+	}
+	if options.envIndex.ambiguous(TESTCONFIGCI_PORT_ENV, "PORT") {
+		ambiguousVars = append(ambiguousVars, ErrTestconfigciPortEnvAmbiguous)
+	}
+	val_Port, from, ok, conflict := lookupEnvWithAliases(lookup, nil, TESTCONFIGCI_PORT_ENV, "PORT")
+	if conflict {
+		conflictVars = append(conflictVars, ErrTestconfigciPortEnvConflict)
+	} else if ok && from != TESTCONFIGCI_PORT_ENV {
+		options.warn(Warning{EnvVar: TESTCONFIGCI_PORT_ENV, Alias: from})
+	}
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Port", EnvVar: TESTCONFIGCI_PORT_ENV, Kind: "missing", sentinel: ErrTestconfigciPortEnvMissing})
+	} else {
+		parsed, err := strconv.Atoi(val_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Port", TESTCONFIGCI_PORT_ENV, ErrTestconfigciPortEnvInvalid, val_Port, false, err))
+		} else {
+			config.Port = parsed
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(conflictVars) > 0 || len(ambiguousVars) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(conflictVars) > 0 {
+			verr = errors.Join(verr, ConflictingEnvVarsError{vars: conflictVars})
+		}
+		if len(ambiguousVars) > 0 {
+			verr = errors.Join(verr, AmbiguousEnvVarsError{vars: ambiguousVars})
+		}
+		return TestConfigCaseInsensitive{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+	envIndex  envIndex
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func withEnvIndex(idx envIndex) LoadOption {
+	return func(o *loadOptions) {
+		o.envIndex = idx
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+type ConflictingEnvVarsError struct {
+	vars []error
+}
+
+func (m ConflictingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ConflictingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are set to different values through their aliases")
+}
+
+type AmbiguousEnvVarsError struct {
+	vars []error
+}
+
+func (m AmbiguousEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m AmbiguousEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are set more than once with different case")
+}
+
+// lookupEnvWithAliases returns the value of the first of names that is set
+// and the name it was read from. It also reports whether two of the names
+// are set to different values. Unless unset is nil, the values it reports
+// true for are skipped as if the name was not set.
+func lookupEnvWithAliases(lookup func(string) (string, bool), unset func(string) bool, names ...string) (value string, from string, ok bool, conflict bool) {
+	for _, name := range names {
+		v, set := lookup(name)
+		if !set || unset != nil && unset(v) {
+			continue
+		}
+		if !ok {
+			value, from, ok = v, name, true
+		} else if v != value {
+			conflict = true
+		}
+	}
+	return value, from, ok, conflict
+}
+
+// envIndex resolves env var names regardless of case. It maps upper-cased
+// names to every variable set under that name.
+type envIndex map[string][]envEntry
+
+type envEntry struct {
+	name  string
+	value string
+}
+
+func newEnvIndex(environ []string) envIndex {
+	idx := envIndex{}
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if name == "" {
+			continue
+		}
+		idx.add(name, value)
+	}
+	return idx
+}
+
+func newEnvIndexFromMap(m map[string]string) envIndex {
+	idx := envIndex{}
+	for name, value := range m {
+		idx.add(name, value)
+	}
+	return idx
+}
+
+func (idx envIndex) add(name, value string) {
+	key := strings.ToUpper(name)
+	idx[key] = append(idx[key], envEntry{name: name, value: value})
+}
+
+// lookup returns the value of the variable matching name regardless of
+// case, preferring an exact match.
+func (idx envIndex) lookup(name string) (string, bool) {
+	entries := idx[strings.ToUpper(name)]
+	for _, e := range entries {
+		if e.name == name {
+			return e.value, true
+		}
+	}
+	if len(entries) > 0 {
+		return entries[0].value, true
+	}
+	return "", false
+}
+
+// ambiguous reports whether any of names is set more than once with
+// different case.
+func (idx envIndex) ambiguous(names ...string) bool {
+	for _, name := range names {
+		if len(idx[strings.ToUpper(name)]) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
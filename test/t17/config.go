@@ -0,0 +1,11 @@
+//go:build testcases
+// +build testcases
+
+package t17
+
+type TestConfigInterpolation struct {
+	Host  string `default:"localhost"`
+	Port  int    `default:"${APP_BASE_PORT:-9000}"`
+	URL   string `default:"http://${APP_HOST}:${APP_PORT}/api"`
+	Price string `default:"$$5"`
+}
@@ -0,0 +1,560 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t17
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	APP_HOST_ENV  = "APP_HOST"
+	APP_PORT_ENV  = "APP_PORT"
+	APP_URL_ENV   = "APP_URL"
+	APP_PRICE_ENV = "APP_PRICE"
+)
+
+var (
+	ErrAppPortEnvInvalid = errors.New(APP_PORT_ENV)
+)
+
+// LoadTestConfigInterpolation reads the config from the environment.
+func LoadTestConfigInterpolation(opts ...LoadOption) (TestConfigInterpolation, error) {
+	return LoadTestConfigInterpolationFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigInterpolationFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigInterpolationFromMap(m map[string]string, opts ...LoadOption) (TestConfigInterpolation, error) {
+	return LoadTestConfigInterpolationFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigInterpolationFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigInterpolationFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigInterpolation, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	sources := []func(string) (string, bool){lookup}
+	if len(options.dotenvFiles) > 0 {
+		values, err := readDotenvFiles(options.dotenvFiles, true)
+		if err != nil {
+			return TestConfigInterpolation{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+	lookup = layerLookups(nil, sources...)
+	defaults := map[string]string{
+		APP_HOST_ENV:  "localhost",
+		APP_PORT_ENV:  "${APP_BASE_PORT:-9000}",
+		APP_URL_ENV:   "http://${APP_HOST}:${APP_PORT}/api",
+		APP_PRICE_ENV: "$$5",
+	}
+	exp := newExpander(lookup, defaults)
+	lookup = exp.lookupExpanded
+
+	var config TestConfigInterpolation
+	var missingVars []error
+	var formatVars []error
+	val_Host, ok := lookup(APP_HOST_ENV)
+	if !ok {
+		val_Host = "localhost"
+		ok = true
+	}
+	if ok {
+		config.Host = val_Host
+	}
+	val_Port, ok := lookup(APP_PORT_ENV)
+	if !ok {
+		val_Port = exp.expandDefault(APP_PORT_ENV)
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Port", APP_PORT_ENV, ErrAppPortEnvInvalid, val_Port, false, err))
+		} else {
+			config.Port = parsed
+		}
+	}
+	val_URL, ok := lookup(APP_URL_ENV)
+	if !ok {
+		val_URL = exp.expandDefault(APP_URL_ENV)
+		ok = true
+	}
+	if ok {
+		config.URL = val_URL
+	}
+	val_Price, ok := lookup(APP_PRICE_ENV)
+	if !ok {
+		val_Price = exp.expandDefault(APP_PRICE_ENV)
+		ok = true
+	}
+	if ok {
+		config.Price = val_Price
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(exp.cycles) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(exp.cycles) > 0 {
+			verr = errors.Join(verr, CyclicEnvVarsError{vars: exp.cycles})
+		}
+		return TestConfigInterpolation{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning   func(Warning)
+	dotenvFiles []string
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+// WithDotenv layers the values of dotenv files under the real environment.
+// Later files take precedence over earlier ones, and files that do not
+// exist are skipped, e.g. WithDotenv(".env", ".env.local").
+func WithDotenv(paths ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenvFiles = append(o.dotenvFiles, paths...)
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+} // CyclicEnvVarsError lists the env vars whose values or defaults reference
+// themselves through ${...}, together with the chain of references.
+type CyclicEnvVarsError struct {
+	vars []error
+}
+
+func (m CyclicEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m CyclicEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " reference themselves")
+}
+
+// layerLookups combines sources, earlier ones taking precedence. The names of
+// each group in aliases belong to one field and are all read from the first
+// source that sets any of them, so that a source wins outright instead of
+// mixing its value with the aliases set in the sources below it.
+func layerLookups(aliases [][]string, sources ...func(string) (string, bool)) func(string) (string, bool) {
+	groups := map[string][]string{}
+	for _, names := range aliases {
+		for _, name := range names {
+			groups[name] = names
+		}
+	}
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			if value, ok := source(name); ok {
+				return value, true
+			}
+			for _, alias := range groups[name] {
+				if alias == name {
+					continue
+				}
+				if _, ok := source(alias); ok {
+					return "", false
+				}
+			}
+		}
+		return "", false
+	}
+}
+
+// expander expands ${VAR} and ${VAR:-fallback} in values and defaults, with
+// $$ standing for a literal $. The fallback is used when VAR is unset or
+// empty. Referenced variables are expanded in turn, falling back to their
+// defaults, and cycles are recorded instead of recursing forever.
+type expander struct {
+	lookup   func(string) (string, bool)
+	defaults map[string]string
+	active   []string
+	cycles   []error
+	reported map[string]bool
+}
+
+func newExpander(lookup func(string) (string, bool), defaults map[string]string) *expander {
+	return &expander{lookup: lookup, defaults: defaults, reported: map[string]bool{}}
+}
+
+// lookupExpanded has the same contract as os.LookupEnv and expands the
+// value of name.
+func (e *expander) lookupExpanded(name string) (string, bool) {
+	raw, ok := e.lookup(name)
+	if !ok {
+		return "", false
+	}
+	return e.expandValueOf(name, raw), true
+}
+
+func (e *expander) expandDefault(name string) string {
+	return e.expandValueOf(name, e.defaults[name])
+}
+
+// value returns the expanded value of a referenced variable, or of its
+// default if it is not set.
+func (e *expander) value(name string) (string, bool) {
+	raw, ok := e.lookup(name)
+	if !ok {
+		raw, ok = e.defaults[name]
+	}
+	if !ok {
+		return "", false
+	}
+	return e.expandValueOf(name, raw), true
+}
+
+func (e *expander) expandValueOf(name string, raw string) string {
+	for i, active := range e.active {
+		if active != name {
+			continue
+		}
+		root := e.active[0]
+		if !e.reported[root] {
+			e.reported[root] = true
+			chain := strings.Join(append(e.active[i:len(e.active):len(e.active)], name), " -> ")
+			e.cycles = append(e.cycles, cycleError{envVar: root, chain: chain})
+		}
+		return ""
+	}
+	if !strings.Contains(raw, "$") {
+		return raw
+	}
+	e.active = append(e.active, name)
+	defer func() {
+		e.active = e.active[:len(e.active)-1]
+	}()
+	return e.expand(raw)
+}
+
+func (e *expander) expand(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end, depth := -1, 0
+			for j := i + 1; j < len(s) && end < 0; j++ {
+				switch s[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+					if depth == 0 {
+						end = j
+					}
+				}
+			}
+			if end < 0 {
+				// an unterminated reference is kept as it is
+				sb.WriteString(s[i:])
+				return sb.String()
+			}
+			name, fallback, hasFallback := strings.Cut(s[i+2:end], ":-")
+			value, ok := e.value(name)
+			if hasFallback && (!ok || value == "") {
+				value = e.expand(fallback)
+			}
+			sb.WriteString(value)
+			i = end
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String()
+}
+
+type cycleError struct {
+	envVar string
+	chain  string
+}
+
+func (e cycleError) Error() string {
+	return e.envVar + " (" + e.chain + ")"
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// readDotenvFiles reads and merges dotenv files, later files taking
+// precedence. Files that do not exist are skipped. escapeDollar is passed
+// on to parseDotenv.
+func readDotenvFiles(paths []string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := parseDotenv(path, string(data), escapeDollar)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// parseDotenv parses the contents of a dotenv file. It supports comments,
+// an optional export prefix, single quoted values (taken literally), double
+// quoted values (with \n, \r, \t, \", \\ and \$ escapes, possibly spanning
+// several lines) and unquoted values, which end at an inline " #" comment.
+// With escapeDollar, the literal dollars of \$ escapes and single quoted
+// values are written as $$, so that interpolation keeps them as they are.
+func parseDotenv(path string, data string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	line := 1
+	fail := func(msg string) error {
+		return errors.New(path + ":" + strconv.Itoa(line) + ": " + msg)
+	}
+	lineEnd := func(i int) int {
+		if end := strings.IndexByte(data[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(data)
+	}
+	i := 0
+	for i < len(data) {
+		switch data[i] {
+		case '\n':
+			line++
+			i++
+			continue
+		case ' ', '\t', '\r':
+			i++
+			continue
+		case '#':
+			i = lineEnd(i)
+			continue
+		}
+
+		end := lineEnd(i)
+		if rest, ok := strings.CutPrefix(data[i:end], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			i += len("export")
+		}
+		eq := strings.IndexByte(data[i:end], '=')
+		if eq < 0 {
+			return nil, fail("expected KEY=value")
+		}
+		key := strings.TrimSpace(data[i : i+eq])
+		if key == "" || strings.ContainsAny(key, " \t'\"#") {
+			return nil, fail("invalid key " + strconv.Quote(key))
+		}
+		i += eq + 1
+		for i < end && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+
+		if i < end && (data[i] == '\'' || data[i] == '"') {
+			quote := data[i]
+			i++
+			startLine := line
+			var sb strings.Builder
+			closed := false
+			for i < len(data) {
+				c := data[i]
+				if c == quote {
+					closed = true
+					i++
+					break
+				}
+				if quote == '"' && c == '\\' && i+1 < len(data) {
+					switch next := data[i+1]; next {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					case '$':
+						if escapeDollar {
+							sb.WriteByte('$')
+						}
+						sb.WriteByte('$')
+					case '"', '\\':
+						sb.WriteByte(next)
+					default:
+						sb.WriteByte(c)
+						sb.WriteByte(next)
+						if next == '\n' {
+							line++
+						}
+					}
+					i += 2
+					continue
+				}
+				if c == '\n' {
+					line++
+				}
+				if quote == '\'' && c == '$' && escapeDollar {
+					sb.WriteByte('$')
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				line = startLine
+				return nil, fail("unterminated quoted value for " + key)
+			}
+			end = lineEnd(i)
+			if rest := strings.TrimSpace(data[i:end]); rest != "" && rest[0] != '#' {
+				return nil, fail("unexpected characters after quoted value for " + key)
+			}
+			values[key] = sb.String()
+		} else {
+			raw := data[i:end]
+			if comment := strings.Index(raw, " #"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			if comment := strings.Index(raw, "\t#"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			values[key] = strings.TrimSpace(raw)
+		}
+		i = end
+	}
+	return values, nil
+}
@@ -0,0 +1,14 @@
+//go:build testcases
+// +build testcases
+
+package t18
+
+type Server struct {
+	Port int `default:"8080" default.prod:"80"`
+}
+
+type TestConfigProfiles struct {
+	LogLevel string `default:"info" default.dev:"debug" default.prod:"warn"`
+	Workers  int    `default.prod:"${APP_CPUS:-4}"` // only optional in prod
+	Server   Server
+}
@@ -0,0 +1,405 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t18
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	APP_LOGLEVEL_ENV    = "APP_LOGLEVEL"
+	APP_WORKERS_ENV     = "APP_WORKERS"
+	APP_SERVER_PORT_ENV = "APP_SERVER_PORT"
+	APP_PROFILE_ENV     = "APP_PROFILE"
+)
+
+var (
+	ErrAppWorkersEnvMissing    = errors.New(APP_WORKERS_ENV)
+	ErrAppWorkersEnvInvalid    = errors.New(APP_WORKERS_ENV)
+	ErrAppServerPortEnvInvalid = errors.New(APP_SERVER_PORT_ENV)
+)
+
+// LoadTestConfigProfiles reads the config from the environment.
+func LoadTestConfigProfiles(opts ...LoadOption) (TestConfigProfiles, error) {
+	return LoadTestConfigProfilesFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigProfilesFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigProfilesFromMap(m map[string]string, opts ...LoadOption) (TestConfigProfiles, error) {
+	return LoadTestConfigProfilesFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigProfilesFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigProfilesFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigProfiles, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	defaults := map[string]string{
+		APP_LOGLEVEL_ENV:    "info",
+		APP_SERVER_PORT_ENV: "8080",
+	}
+	profile := options.profile
+	if profile == "" {
+		profile, _ = lookup(APP_PROFILE_ENV)
+	}
+	switch profile {
+	case "":
+	case "dev":
+		defaults[APP_LOGLEVEL_ENV] = "debug"
+	case "prod":
+		defaults[APP_LOGLEVEL_ENV] = "warn"
+		defaults[APP_WORKERS_ENV] = "${APP_CPUS:-4}"
+		defaults[APP_SERVER_PORT_ENV] = "80"
+	default:
+		return TestConfigProfiles{}, UnknownProfileError{Profile: profile, Known: []string{"dev", "prod"}}
+	}
+	exp := newExpander(lookup, defaults)
+	lookup = exp.lookupExpanded
+
+	var config TestConfigProfiles
+	var missingVars []error
+	var formatVars []error
+	val_LogLevel, ok := lookup(APP_LOGLEVEL_ENV)
+	if !ok {
+		_, ok = defaults[APP_LOGLEVEL_ENV]
+		if ok {
+			val_LogLevel = exp.expandDefault(APP_LOGLEVEL_ENV)
+		}
+	}
+	if ok {
+		config.LogLevel = val_LogLevel
+	}
+	val_Workers, ok := lookup(APP_WORKERS_ENV)
+	if !ok {
+		_, ok = defaults[APP_WORKERS_ENV]
+		if ok {
+			val_Workers = exp.expandDefault(APP_WORKERS_ENV)
+		}
+		if !ok {
+			missingVars = append(missingVars, FieldError{Path: "Workers", EnvVar: APP_WORKERS_ENV, Kind: "missing", sentinel: ErrAppWorkersEnvMissing})
+		}
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Workers)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Workers", APP_WORKERS_ENV, ErrAppWorkersEnvInvalid, val_Workers, false, err))
+		} else {
+			config.Workers = parsed
+		}
+	}
+	val_Server_Port, ok := lookup(APP_SERVER_PORT_ENV)
+	if !ok {
+		_, ok = defaults[APP_SERVER_PORT_ENV]
+		if ok {
+			val_Server_Port = exp.expandDefault(APP_SERVER_PORT_ENV)
+		}
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Server_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Server.Port", APP_SERVER_PORT_ENV, ErrAppServerPortEnvInvalid, val_Server_Port, false, err))
+		} else {
+			config.Server.Port = parsed
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(exp.cycles) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(exp.cycles) > 0 {
+			verr = errors.Join(verr, CyclicEnvVarsError{vars: exp.cycles})
+		}
+		return TestConfigProfiles{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+	profile   string
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+// WithProfile selects the profile whose defaults apply, taking precedence
+// over the <PREFIX>_PROFILE env var.
+func WithProfile(name string) LoadOption {
+	return func(o *loadOptions) {
+		o.profile = name
+	}
+}
+
+// UnknownProfileError is returned when the selected profile has no defaults
+// defined for it.
+type UnknownProfileError struct {
+	Profile string
+	Known   []string
+}
+
+func (e UnknownProfileError) Error() string {
+	return "unknown profile \"" + e.Profile + "\", expected one of " + strings.Join(e.Known, ", ")
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+} // CyclicEnvVarsError lists the env vars whose values or defaults reference
+// themselves through ${...}, together with the chain of references.
+type CyclicEnvVarsError struct {
+	vars []error
+}
+
+func (m CyclicEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m CyclicEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " reference themselves")
+}
+
+// expander expands ${VAR} and ${VAR:-fallback} in values and defaults, with
+// $$ standing for a literal $. The fallback is used when VAR is unset or
+// empty. Referenced variables are expanded in turn, falling back to their
+// defaults, and cycles are recorded instead of recursing forever.
+type expander struct {
+	lookup   func(string) (string, bool)
+	defaults map[string]string
+	active   []string
+	cycles   []error
+	reported map[string]bool
+}
+
+func newExpander(lookup func(string) (string, bool), defaults map[string]string) *expander {
+	return &expander{lookup: lookup, defaults: defaults, reported: map[string]bool{}}
+}
+
+// lookupExpanded has the same contract as os.LookupEnv and expands the
+// value of name.
+func (e *expander) lookupExpanded(name string) (string, bool) {
+	raw, ok := e.lookup(name)
+	if !ok {
+		return "", false
+	}
+	return e.expandValueOf(name, raw), true
+}
+
+func (e *expander) expandDefault(name string) string {
+	return e.expandValueOf(name, e.defaults[name])
+}
+
+// value returns the expanded value of a referenced variable, or of its
+// default if it is not set.
+func (e *expander) value(name string) (string, bool) {
+	raw, ok := e.lookup(name)
+	if !ok {
+		raw, ok = e.defaults[name]
+	}
+	if !ok {
+		return "", false
+	}
+	return e.expandValueOf(name, raw), true
+}
+
+func (e *expander) expandValueOf(name string, raw string) string {
+	for i, active := range e.active {
+		if active != name {
+			continue
+		}
+		root := e.active[0]
+		if !e.reported[root] {
+			e.reported[root] = true
+			chain := strings.Join(append(e.active[i:len(e.active):len(e.active)], name), " -> ")
+			e.cycles = append(e.cycles, cycleError{envVar: root, chain: chain})
+		}
+		return ""
+	}
+	if !strings.Contains(raw, "$") {
+		return raw
+	}
+	e.active = append(e.active, name)
+	defer func() {
+		e.active = e.active[:len(e.active)-1]
+	}()
+	return e.expand(raw)
+}
+
+func (e *expander) expand(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end, depth := -1, 0
+			for j := i + 1; j < len(s) && end < 0; j++ {
+				switch s[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+					if depth == 0 {
+						end = j
+					}
+				}
+			}
+			if end < 0 {
+				// an unterminated reference is kept as it is
+				sb.WriteString(s[i:])
+				return sb.String()
+			}
+			name, fallback, hasFallback := strings.Cut(s[i+2:end], ":-")
+			value, ok := e.value(name)
+			if hasFallback && (!ok || value == "") {
+				value = e.expand(fallback)
+			}
+			sb.WriteString(value)
+			i = end
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String()
+}
+
+type cycleError struct {
+	envVar string
+	chain  string
+}
+
+func (e cycleError) Error() string {
+	return e.envVar + " (" + e.chain + ")"
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
@@ -0,0 +1,14 @@
+//go:build testcases
+// +build testcases
+
+package t19
+
+import "time"
+
+type TestConfigValidation struct {
+	Port    int           `min:"1" max:"65535" default:"8080"`
+	Ratio   float32       `min:"0" max:"1" default:"0.5"`
+	Timeout time.Duration `min:"1s" max:"1m" default:"10s"`
+	Retries uint8         `max:"10" default:"3"`
+	Name    string        `minlen:"3" maxlen:"8" pattern:"^[a-z-]+$" default:"svc"`
+}
@@ -0,0 +1,311 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t19
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	APP_PORT_ENV    = "APP_PORT"
+	APP_RATIO_ENV   = "APP_RATIO"
+	APP_TIMEOUT_ENV = "APP_TIMEOUT"
+	APP_RETRIES_ENV = "APP_RETRIES"
+	APP_NAME_ENV    = "APP_NAME"
+)
+
+var (
+	ErrAppPortEnvInvalid    = errors.New(APP_PORT_ENV)
+	ErrAppRatioEnvInvalid   = errors.New(APP_RATIO_ENV)
+	ErrAppTimeoutEnvInvalid = errors.New(APP_TIMEOUT_ENV)
+	ErrAppRetriesEnvInvalid = errors.New(APP_RETRIES_ENV)
+	pattern_Name            = regexp.MustCompile("^[a-z-]+$")
+)
+
+// LoadTestConfigValidation reads the config from the environment.
+func LoadTestConfigValidation(opts ...LoadOption) (TestConfigValidation, error) {
+	return LoadTestConfigValidationFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigValidationFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigValidationFromMap(m map[string]string, opts ...LoadOption) (TestConfigValidation, error) {
+	return LoadTestConfigValidationFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigValidationFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigValidationFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigValidation, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigValidation
+	var missingVars []error
+	var formatVars []error
+	var violations []error
+	val_Port, ok := lookup(APP_PORT_ENV)
+	if !ok {
+		val_Port = "8080"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Port", APP_PORT_ENV, ErrAppPortEnvInvalid, val_Port, false, err))
+		} else {
+			config.Port = parsed
+			if config.Port < 1 {
+				violations = append(violations, violation{envVar: APP_PORT_ENV, constraint: "min 1"})
+			}
+			if config.Port > 65535 {
+				violations = append(violations, violation{envVar: APP_PORT_ENV, constraint: "max 65535"})
+			}
+		}
+	}
+	val_Ratio, ok := lookup(APP_RATIO_ENV)
+	if !ok {
+		val_Ratio = "0.5"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.ParseFloat(val_Ratio, 32)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Ratio", APP_RATIO_ENV, ErrAppRatioEnvInvalid, val_Ratio, false, err))
+		} else {
+			config.Ratio = float32(parsed)
+			if config.Ratio < 0 {
+				violations = append(violations, violation{envVar: APP_RATIO_ENV, constraint: "min 0"})
+			}
+			if config.Ratio > 1 {
+				violations = append(violations, violation{envVar: APP_RATIO_ENV, constraint: "max 1"})
+			}
+		}
+	}
+	val_Timeout, ok := lookup(APP_TIMEOUT_ENV)
+	if !ok {
+		val_Timeout = "10s"
+		ok = true
+	}
+	if ok {
+		parsed, err := time.ParseDuration(val_Timeout)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Timeout", APP_TIMEOUT_ENV, ErrAppTimeoutEnvInvalid, val_Timeout, false, err))
+		} else {
+			config.Timeout = parsed
+			if config.Timeout < 1000000000 {
+				violations = append(violations, violation{envVar: APP_TIMEOUT_ENV, constraint: "min 1s"})
+			}
+			if config.Timeout > 60000000000 {
+				violations = append(violations, violation{envVar: APP_TIMEOUT_ENV, constraint: "max 1m"})
+			}
+		}
+	}
+	val_Retries, ok := lookup(APP_RETRIES_ENV)
+	if !ok {
+		val_Retries = "3"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.ParseUint(val_Retries, 10, 8)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Retries", APP_RETRIES_ENV, ErrAppRetriesEnvInvalid, val_Retries, false, err))
+		} else {
+			config.Retries = uint8(parsed)
+			if config.Retries > 10 {
+				violations = append(violations, violation{envVar: APP_RETRIES_ENV, constraint: "max 10"})
+			}
+		}
+	}
+	val_Name, ok := lookup(APP_NAME_ENV)
+	if !ok {
+		val_Name = "svc"
+		ok = true
+	}
+	if ok {
+		config.Name = val_Name
+		if utf8.RuneCountInString(config.Name) < 3 {
+			violations = append(violations, violation{envVar: APP_NAME_ENV, constraint: "minlen 3"})
+		}
+		if utf8.RuneCountInString(config.Name) > 8 {
+			violations = append(violations, violation{envVar: APP_NAME_ENV, constraint: "maxlen 8"})
+		}
+		if !pattern_Name.MatchString(config.Name) {
+			violations = append(violations, violation{envVar: APP_NAME_ENV, constraint: "pattern ^[a-z-]+$"})
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(violations) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(violations) > 0 {
+			verr = errors.Join(verr, ValidationError{vars: violations})
+		}
+		return TestConfigValidation{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// ValidationError lists the env vars whose values violate the constraints
+// of their fields, each with the constraint that failed.
+type ValidationError struct {
+	vars []error
+}
+
+func (m ValidationError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ValidationError) Error() string {
+	return joinEnvVars("envs ", m.vars, " violate their constraints")
+}
+
+type violation struct {
+	envVar     string
+	constraint string
+}
+
+func (v violation) Error() string {
+	return v.envVar + " (" + v.constraint + ")"
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
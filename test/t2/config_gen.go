@@ -29,45 +29,66 @@ var (
 	ErrTestconfigcopyRetriesEnvInvalid = errors.New(TESTCONFIGCOPY_RETRIES_ENV)
 )
 
-func LoadTestConfigCopy() (TestConfigCopy, error) {
+// LoadTestConfigCopy reads the config from the environment.
+func LoadTestConfigCopy(opts ...LoadOption) (TestConfigCopy, error) {
+	return LoadTestConfigCopyFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigCopyFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigCopyFromMap(m map[string]string, opts ...LoadOption) (TestConfigCopy, error) {
+	return LoadTestConfigCopyFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigCopyFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigCopyFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigCopy, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var config TestConfigCopy
 	var missingVars []error
 	var formatVars []error
-	val_AppName, ok := os.LookupEnv(TESTCONFIGCOPY_APPNAME_ENV)
+	val_AppName, ok := lookup(TESTCONFIGCOPY_APPNAME_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigcopyAppnameEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "AppName", EnvVar: TESTCONFIGCOPY_APPNAME_ENV, Kind: "missing", sentinel: ErrTestconfigcopyAppnameEnvMissing})
 	} else {
 		config.AppName = val_AppName
 	}
-	val_Debug, ok := os.LookupEnv(TESTCONFIGCOPY_DEBUG_ENV)
+	val_Debug, ok := lookup(TESTCONFIGCOPY_DEBUG_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigcopyDebugEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Debug", EnvVar: TESTCONFIGCOPY_DEBUG_ENV, Kind: "missing", sentinel: ErrTestconfigcopyDebugEnvMissing})
 	} else {
 		parsed, err := strconv.ParseBool(val_Debug)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigcopyDebugEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Debug", TESTCONFIGCOPY_DEBUG_ENV, ErrTestconfigcopyDebugEnvInvalid, val_Debug, false, err))
 		} else {
 			config.Debug = parsed
 		}
 	}
-	val_Timeout, ok := os.LookupEnv(TESTCONFIGCOPY_TIMEOUT_ENV)
+	val_Timeout, ok := lookup(TESTCONFIGCOPY_TIMEOUT_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigcopyTimeoutEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Timeout", EnvVar: TESTCONFIGCOPY_TIMEOUT_ENV, Kind: "missing", sentinel: ErrTestconfigcopyTimeoutEnvMissing})
 	} else {
 		parsed, err := time.ParseDuration(val_Timeout)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigcopyTimeoutEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Timeout", TESTCONFIGCOPY_TIMEOUT_ENV, ErrTestconfigcopyTimeoutEnvInvalid, val_Timeout, false, err))
 		} else {
 			config.Timeout = parsed
 		}
 	}
-	val_Retries, ok := os.LookupEnv(TESTCONFIGCOPY_RETRIES_ENV)
+	val_Retries, ok := lookup(TESTCONFIGCOPY_RETRIES_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigcopyRetriesEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Retries", EnvVar: TESTCONFIGCOPY_RETRIES_ENV, Kind: "missing", sentinel: ErrTestconfigcopyRetriesEnvMissing})
 	} else {
 		parsed, err := strconv.Atoi(val_Retries)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigcopyRetriesEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Retries", TESTCONFIGCOPY_RETRIES_ENV, ErrTestconfigcopyRetriesEnvInvalid, val_Retries, false, err))
 		} else {
 			config.Retries = parsed
 		}
@@ -87,6 +108,79 @@ func LoadTestConfigCopy() (TestConfigCopy, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -96,14 +190,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -115,12 +202,31 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return fe
 }
@@ -0,0 +1,14 @@
+//go:build testcases
+// +build testcases
+
+package t20
+
+type TestConfigRequired struct {
+	APIKey   string `required:"nonempty"`
+	Region   string `required:"nonempty" default:"eu"`
+	Tracing  bool   `optional:"true"`
+	Endpoint string `optional:"true" required:"nonempty"`
+	Name     string
+	Port     int    `normalize:"trim" required:"nonempty" default:"80"`
+	Database string `env:"APP_DB_URL" aliases:"DATABASE_URL" required:"nonempty" default:"postgres://localhost"`
+}
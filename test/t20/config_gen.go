@@ -0,0 +1,300 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t20
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	APP_APIKEY_ENV   = "APP_APIKEY"
+	APP_REGION_ENV   = "APP_REGION"
+	APP_TRACING_ENV  = "APP_TRACING"
+	APP_ENDPOINT_ENV = "APP_ENDPOINT"
+	APP_NAME_ENV     = "APP_NAME"
+	APP_PORT_ENV     = "APP_PORT"
+	APP_DB_URL_ENV   = "APP_DB_URL"
+)
+
+var (
+	ErrAppApikeyEnvMissing  = errors.New(APP_APIKEY_ENV)
+	ErrAppTracingEnvInvalid = errors.New(APP_TRACING_ENV)
+	ErrAppNameEnvMissing    = errors.New(APP_NAME_ENV)
+	ErrAppPortEnvInvalid    = errors.New(APP_PORT_ENV)
+	ErrAppDbUrlEnvConflict  = errors.New(APP_DB_URL_ENV)
+)
+
+// LoadTestConfigRequired reads the config from the environment.
+func LoadTestConfigRequired(opts ...LoadOption) (TestConfigRequired, error) {
+	return LoadTestConfigRequiredFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigRequiredFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigRequiredFromMap(m map[string]string, opts ...LoadOption) (TestConfigRequired, error) {
+	return LoadTestConfigRequiredFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigRequiredFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigRequiredFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigRequired, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigRequired
+	var missingVars []error
+	var formatVars []error
+	var conflictVars []error
+	val_APIKey, ok := lookup(APP_APIKEY_ENV)
+	if val_APIKey == "" {
+		ok = false
+	}
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "APIKey", EnvVar: APP_APIKEY_ENV, Kind: "missing", sentinel: ErrAppApikeyEnvMissing})
+	} else {
+		config.APIKey = val_APIKey
+	}
+	val_Region, ok := lookup(APP_REGION_ENV)
+	if val_Region == "" {
+		ok = false
+	}
+	if !ok {
+		val_Region = "eu"
+		ok = true
+	}
+	if ok {
+		config.Region = val_Region
+	}
+	val_Tracing, ok := lookup(APP_TRACING_ENV)
+	if ok {
+		parsed, err := strconv.ParseBool(val_Tracing)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Tracing", APP_TRACING_ENV, ErrAppTracingEnvInvalid, val_Tracing, false, err))
+		} else {
+			config.Tracing = parsed
+		}
+	}
+	val_Endpoint, ok := lookup(APP_ENDPOINT_ENV)
+	if val_Endpoint == "" {
+		ok = false
+	}
+	if ok {
+		config.Endpoint = val_Endpoint
+	}
+	val_Name, ok := lookup(APP_NAME_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Name", EnvVar: APP_NAME_ENV, Kind: "missing", sentinel: ErrAppNameEnvMissing})
+	} else {
+		config.Name = val_Name
+	}
+	val_Port, ok := lookup(APP_PORT_ENV)
+	if strings.TrimSpace(val_Port) == "" {
+		ok = false
+	}
+	if !ok {
+		val_Port = "80"
+		ok = true
+	}
+	if ok {
+		val_Port = strings.TrimSpace(val_Port)
+		parsed, err := strconv.Atoi(val_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Port", APP_PORT_ENV, ErrAppPortEnvInvalid, val_Port, false, err))
+		} else {
+			config.Port = parsed
+		}
+	}
+	val_Database, from, ok, conflict := lookupEnvWithAliases(lookup, func(value string) bool { return value == "" }, APP_DB_URL_ENV, "DATABASE_URL")
+	if conflict {
+		conflictVars = append(conflictVars, ErrAppDbUrlEnvConflict)
+	} else if ok && from != APP_DB_URL_ENV {
+		options.warn(Warning{EnvVar: APP_DB_URL_ENV, Alias: from})
+	}
+	if !ok {
+		val_Database = "postgres://localhost"
+		ok = true
+	}
+	if ok {
+		config.Database = val_Database
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(conflictVars) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(conflictVars) > 0 {
+			verr = errors.Join(verr, ConflictingEnvVarsError{vars: conflictVars})
+		}
+		return TestConfigRequired{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+type ConflictingEnvVarsError struct {
+	vars []error
+}
+
+func (m ConflictingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ConflictingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are set to different values through their aliases")
+}
+
+// lookupEnvWithAliases returns the value of the first of names that is set
+// and the name it was read from. It also reports whether two of the names
+// are set to different values. Unless unset is nil, the values it reports
+// true for are skipped as if the name was not set.
+func lookupEnvWithAliases(lookup func(string) (string, bool), unset func(string) bool, names ...string) (value string, from string, ok bool, conflict bool) {
+	for _, name := range names {
+		v, set := lookup(name)
+		if !set || unset != nil && unset(v) {
+			continue
+		}
+		if !ok {
+			value, from, ok = v, name, true
+		} else if v != value {
+			conflict = true
+		}
+	}
+	return value, from, ok, conflict
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
@@ -0,0 +1,9 @@
+//go:build testcases
+// +build testcases
+
+package t21
+
+type TestConfigEmptyUnset struct {
+	Name string
+	Port int `default:"8080"`
+}
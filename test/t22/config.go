@@ -0,0 +1,42 @@
+//go:build testcases
+// +build testcases
+
+package t22
+
+import "errors"
+
+type TLS struct {
+	Enabled  bool   `default:"false"`
+	CertFile string `optional:"true"`
+}
+
+type Server struct {
+	Port int `default:"8080"`
+	TLS  TLS
+}
+
+func (s *Server) Validate() error {
+	if s.Port == 22 {
+		return errors.New("port 22 is reserved")
+	}
+	return nil
+}
+
+// Meta is embedded, which the generator leaves alone.
+type Meta struct {
+	Owner string
+}
+
+type TestConfigValidate struct {
+	*Meta
+	Name   string `default:"app"`
+	Server Server
+	Admin  Server
+}
+
+func (c TestConfigValidate) Validate() error {
+	if c.Server.Port == c.Admin.Port {
+		return errors.New("server and admin ports must differ")
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t22
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	APP_NAME_ENV                = "APP_NAME"
+	APP_SERVER_PORT_ENV         = "APP_SERVER_PORT"
+	APP_SERVER_TLS_ENABLED_ENV  = "APP_SERVER_TLS_ENABLED"
+	APP_SERVER_TLS_CERTFILE_ENV = "APP_SERVER_TLS_CERTFILE"
+	APP_ADMIN_PORT_ENV          = "APP_ADMIN_PORT"
+	APP_ADMIN_TLS_ENABLED_ENV   = "APP_ADMIN_TLS_ENABLED"
+	APP_ADMIN_TLS_CERTFILE_ENV  = "APP_ADMIN_TLS_CERTFILE"
+)
+
+var (
+	ErrAppServerPortEnvInvalid       = errors.New(APP_SERVER_PORT_ENV)
+	ErrAppServerTlsEnabledEnvInvalid = errors.New(APP_SERVER_TLS_ENABLED_ENV)
+	ErrAppAdminPortEnvInvalid        = errors.New(APP_ADMIN_PORT_ENV)
+	ErrAppAdminTlsEnabledEnvInvalid  = errors.New(APP_ADMIN_TLS_ENABLED_ENV)
+)
+
+func init() {
+	fieldInfos[APP_NAME_ENV] = fieldInfo{path: "Name", typ: "string", def: "app", hasDefault: true}
+	fieldInfos[APP_SERVER_PORT_ENV] = fieldInfo{path: "Server.Port", typ: "int (e.g. 42)", def: "8080", hasDefault: true}
+	fieldInfos[APP_SERVER_TLS_ENABLED_ENV] = fieldInfo{path: "Server.TLS.Enabled", typ: "bool (true or false)", def: "false", hasDefault: true}
+	fieldInfos[APP_SERVER_TLS_CERTFILE_ENV] = fieldInfo{path: "Server.TLS.CertFile", typ: "string"}
+	fieldInfos[APP_ADMIN_PORT_ENV] = fieldInfo{path: "Admin.Port", typ: "int (e.g. 42)", def: "8080", hasDefault: true}
+	fieldInfos[APP_ADMIN_TLS_ENABLED_ENV] = fieldInfo{path: "Admin.TLS.Enabled", typ: "bool (true or false)", def: "false", hasDefault: true}
+	fieldInfos[APP_ADMIN_TLS_CERTFILE_ENV] = fieldInfo{path: "Admin.TLS.CertFile", typ: "string"}
+}
+
+// LoadTestConfigValidate reads the config from the environment.
+func LoadTestConfigValidate(opts ...LoadOption) (TestConfigValidate, error) {
+	return LoadTestConfigValidateFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigValidateFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigValidateFromMap(m map[string]string, opts ...LoadOption) (TestConfigValidate, error) {
+	return LoadTestConfigValidateFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigValidateFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigValidateFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigValidate, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigValidate
+	var missingVars []error
+	var formatVars []error
+	val_Name, ok := lookup(APP_NAME_ENV)
+	if !ok {
+		val_Name = "app"
+		ok = true
+	}
+	if ok {
+		config.Name = val_Name
+	}
+	val_Server_Port, ok := lookup(APP_SERVER_PORT_ENV)
+	if !ok {
+		val_Server_Port = "8080"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Server_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Server.Port", APP_SERVER_PORT_ENV, ErrAppServerPortEnvInvalid, val_Server_Port, false, err))
+		} else {
+			config.Server.Port = parsed
+		}
+	}
+	val_Server_TLS_Enabled, ok := lookup(APP_SERVER_TLS_ENABLED_ENV)
+	if !ok {
+		val_Server_TLS_Enabled = "false"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.ParseBool(val_Server_TLS_Enabled)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Server.TLS.Enabled", APP_SERVER_TLS_ENABLED_ENV, ErrAppServerTlsEnabledEnvInvalid, val_Server_TLS_Enabled, false, err))
+		} else {
+			config.Server.TLS.Enabled = parsed
+		}
+	}
+	val_Server_TLS_CertFile, ok := lookup(APP_SERVER_TLS_CERTFILE_ENV)
+	if ok {
+		config.Server.TLS.CertFile = val_Server_TLS_CertFile
+	}
+	val_Admin_Port, ok := lookup(APP_ADMIN_PORT_ENV)
+	if !ok {
+		val_Admin_Port = "8080"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Admin_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Admin.Port", APP_ADMIN_PORT_ENV, ErrAppAdminPortEnvInvalid, val_Admin_Port, false, err))
+		} else {
+			config.Admin.Port = parsed
+		}
+	}
+	val_Admin_TLS_Enabled, ok := lookup(APP_ADMIN_TLS_ENABLED_ENV)
+	if !ok {
+		val_Admin_TLS_Enabled = "false"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.ParseBool(val_Admin_TLS_Enabled)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Admin.TLS.Enabled", APP_ADMIN_TLS_ENABLED_ENV, ErrAppAdminTlsEnabledEnvInvalid, val_Admin_TLS_Enabled, false, err))
+		} else {
+			config.Admin.TLS.Enabled = parsed
+		}
+	}
+	val_Admin_TLS_CertFile, ok := lookup(APP_ADMIN_TLS_CERTFILE_ENV)
+	if ok {
+		config.Admin.TLS.CertFile = val_Admin_TLS_CertFile
+	}
+
+	// sections are only validated once all of their fields are loaded
+	var sectionErrs []error
+	if len(missingVars) == 0 && len(formatVars) == 0 {
+		if err := config.Server.TLS.Validate(); err != nil {
+			sectionErrs = append(sectionErrs, SectionError{Config: "TestConfigValidate", Section: "Server.TLS", Err: err})
+		}
+		if err := config.Admin.TLS.Validate(); err != nil {
+			sectionErrs = append(sectionErrs, SectionError{Config: "TestConfigValidate", Section: "Admin.TLS", Err: err})
+		}
+		if err := config.Server.Validate(); err != nil {
+			sectionErrs = append(sectionErrs, SectionError{Config: "TestConfigValidate", Section: "Server", Err: err})
+		}
+		if err := config.Admin.Validate(); err != nil {
+			sectionErrs = append(sectionErrs, SectionError{Config: "TestConfigValidate", Section: "Admin", Err: err})
+		}
+		if err := config.Validate(); err != nil {
+			sectionErrs = append(sectionErrs, SectionError{Config: "TestConfigValidate", Section: "", Err: err})
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(sectionErrs) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		verr = errors.Join(append([]error{verr}, sectionErrs...)...)
+		return TestConfigValidate{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// fieldInfo describes a field of a config for FormatConfigError.
+type fieldInfo struct {
+	path       string
+	typ        string // type with an example value, e.g. duration (e.g. 30s)
+	def        string
+	hasDefault bool
+	doc        string
+}
+
+// fieldInfos holds the fields of every config of the package, keyed by env
+// var. Each config adds its fields when the package is initialized.
+var fieldInfos = map[string]fieldInfo{}
+
+// FormatConfigError formats an error returned by a Load function as a table
+// with one row per env var at fault, showing its field, type with an example
+// value, default, what went wrong and the doc comment of the field. Errors
+// that are not about a single env var, such as those of Validate methods,
+// are listed below the table.
+func FormatConfigError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var rows [][2]string // env var and problem
+	var others []string
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case FieldError:
+			if e.Kind == "missing" {
+				rows = append(rows, [2]string{e.EnvVar, "not set"})
+			} else {
+				rows = append(rows, [2]string{e.EnvVar, "invalid value " + strconv.Quote(e.Value) + ": " + e.reason})
+			}
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		default:
+			others = append(others, err.Error())
+		}
+	}
+	walk(err)
+
+	var table strings.Builder
+	if len(rows) > 0 {
+		w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		w.Write([]byte("ENV VAR\tFIELD\tTYPE\tDEFAULT\tPROBLEM\tDESCRIPTION\n"))
+		for _, row := range rows {
+			info, ok := fieldInfos[row[0]]
+			if !ok {
+				info = fieldInfo{path: "-", typ: "-"}
+			}
+			def := "-"
+			if info.hasDefault {
+				def = strconv.Quote(info.def)
+			}
+			w.Write([]byte(strings.Join([]string{row[0], info.path, info.typ, def, row[1], info.doc}, "\t") + "\n"))
+		}
+		w.Flush()
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+		if line = strings.TrimRight(line, " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(append(lines, others...), "\n")
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// SectionError is an error returned by the Validate method of a section of
+// the config, labelled with the field path of the section.
+type SectionError struct {
+	Config  string // name of the config struct
+	Section string // empty for the config struct itself
+	Err     error
+}
+
+func (e SectionError) Error() string {
+	if e.Section == "" {
+		return e.Config + ": " + e.Err.Error()
+	}
+	return e.Section + ": " + e.Err.Error()
+}
+
+func (e SectionError) Unwrap() error {
+	return e.Err
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
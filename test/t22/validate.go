@@ -0,0 +1,15 @@
+//go:build testcases
+// +build testcases
+
+package t22
+
+import "errors"
+
+// Validate is declared apart from the struct to check that the generator
+// looks at the whole package.
+func (t TLS) Validate() error {
+	if t.Enabled && t.CertFile == "" {
+		return errors.New("a cert file is required when TLS is enabled")
+	}
+	return nil
+}
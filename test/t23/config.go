@@ -0,0 +1,19 @@
+//go:build testcases
+// +build testcases
+
+package t23
+
+type TLS struct {
+	Enabled  bool   `default:"false"`
+	CertFile string `requiredif:"Enabled=true"`
+}
+
+type TestConfigConditions struct {
+	Mode     string `default:"dev"`
+	Seed     int    `requiredif:"Mode=test"`
+	TLS      TLS
+	Username string `optional:"true"`
+	Password string `requiredwith:"Username"`
+	Token    string `optional:"true" excludes:"Username,Password"`
+	Region   string `default:"eu" excludes:"Token"`
+}
@@ -0,0 +1,319 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t23
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	APP_MODE_ENV         = "APP_MODE"
+	APP_SEED_ENV         = "APP_SEED"
+	APP_TLS_ENABLED_ENV  = "APP_TLS_ENABLED"
+	APP_TLS_CERTFILE_ENV = "APP_TLS_CERTFILE"
+	APP_USERNAME_ENV     = "APP_USERNAME"
+	APP_PASSWORD_ENV     = "APP_PASSWORD"
+	APP_TOKEN_ENV        = "APP_TOKEN"
+	APP_REGION_ENV       = "APP_REGION"
+)
+
+var (
+	ErrAppSeedEnvInvalid       = errors.New(APP_SEED_ENV)
+	ErrAppTlsEnabledEnvInvalid = errors.New(APP_TLS_ENABLED_ENV)
+)
+
+// LoadTestConfigConditions reads the config from the environment.
+func LoadTestConfigConditions(opts ...LoadOption) (TestConfigConditions, error) {
+	return LoadTestConfigConditionsFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigConditionsFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigConditionsFromMap(m map[string]string, opts ...LoadOption) (TestConfigConditions, error) {
+	return LoadTestConfigConditionsFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigConditionsFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigConditionsFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigConditions, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigConditions
+	var missingVars []error
+	var formatVars []error
+	var violations []error
+	val_Mode, ok := lookup(APP_MODE_ENV)
+	if !ok {
+		val_Mode = "dev"
+		ok = true
+	}
+	if ok {
+		config.Mode = val_Mode
+	}
+	val_Seed, ok := lookup(APP_SEED_ENV)
+	set_Seed := ok
+	if ok {
+		parsed, err := strconv.Atoi(val_Seed)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Seed", APP_SEED_ENV, ErrAppSeedEnvInvalid, val_Seed, false, err))
+		} else {
+			config.Seed = parsed
+		}
+	}
+	val_TLS_Enabled, ok := lookup(APP_TLS_ENABLED_ENV)
+	if !ok {
+		val_TLS_Enabled = "false"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.ParseBool(val_TLS_Enabled)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("TLS.Enabled", APP_TLS_ENABLED_ENV, ErrAppTlsEnabledEnvInvalid, val_TLS_Enabled, false, err))
+		} else {
+			config.TLS.Enabled = parsed
+		}
+	}
+	val_TLS_CertFile, ok := lookup(APP_TLS_CERTFILE_ENV)
+	set_TLS_CertFile := ok
+	if ok {
+		config.TLS.CertFile = val_TLS_CertFile
+	}
+	val_Username, ok := lookup(APP_USERNAME_ENV)
+	set_Username := ok
+	if ok {
+		config.Username = val_Username
+	}
+	val_Password, ok := lookup(APP_PASSWORD_ENV)
+	set_Password := ok
+	if ok {
+		config.Password = val_Password
+	}
+	val_Token, ok := lookup(APP_TOKEN_ENV)
+	set_Token := ok
+	if ok {
+		config.Token = val_Token
+	}
+	val_Region, ok := lookup(APP_REGION_ENV)
+	set_Region := ok
+	if !ok {
+		val_Region = "eu"
+		ok = true
+	}
+	if ok {
+		config.Region = val_Region
+	}
+
+	if config.Mode == "test" && !set_Seed {
+		violations = append(violations, ConditionViolation{Rule: "requiredif", Field: "Seed", EnvVar: APP_SEED_ENV, OtherField: "Mode", OtherEnvVar: APP_MODE_ENV, OtherValue: "test"})
+	}
+	if config.TLS.Enabled && !set_TLS_CertFile {
+		violations = append(violations, ConditionViolation{Rule: "requiredif", Field: "TLS.CertFile", EnvVar: APP_TLS_CERTFILE_ENV, OtherField: "TLS.Enabled", OtherEnvVar: APP_TLS_ENABLED_ENV, OtherValue: "true"})
+	}
+	if set_Username && !set_Password {
+		violations = append(violations, ConditionViolation{Rule: "requiredwith", Field: "Password", EnvVar: APP_PASSWORD_ENV, OtherField: "Username", OtherEnvVar: APP_USERNAME_ENV})
+	}
+	if set_Token && set_Username {
+		violations = append(violations, ConditionViolation{Rule: "excludes", Field: "Token", EnvVar: APP_TOKEN_ENV, OtherField: "Username", OtherEnvVar: APP_USERNAME_ENV})
+	}
+	if set_Token && set_Password {
+		violations = append(violations, ConditionViolation{Rule: "excludes", Field: "Token", EnvVar: APP_TOKEN_ENV, OtherField: "Password", OtherEnvVar: APP_PASSWORD_ENV})
+	}
+	if set_Region && set_Token {
+		violations = append(violations, ConditionViolation{Rule: "excludes", Field: "Region", EnvVar: APP_REGION_ENV, OtherField: "Token", OtherEnvVar: APP_TOKEN_ENV})
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(violations) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(violations) > 0 {
+			verr = errors.Join(verr, ValidationError{vars: violations})
+		}
+		return TestConfigConditions{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// ValidationError lists the env vars whose values violate the constraints
+// of their fields, each with the constraint that failed.
+type ValidationError struct {
+	vars []error
+}
+
+func (m ValidationError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ValidationError) Error() string {
+	return joinEnvVars("envs ", m.vars, " violate their constraints")
+}
+
+// ConditionViolation is a violated requiredif, requiredwith or excludes tag.
+// It names both fields and their env vars.
+type ConditionViolation struct {
+	Rule        string // requiredif, requiredwith or excludes
+	Field       string
+	EnvVar      string
+	OtherField  string
+	OtherEnvVar string
+	OtherValue  string // value of OtherField that makes Field required, for requiredif
+}
+
+func (v ConditionViolation) Error() string {
+	switch v.Rule {
+	case "requiredif":
+		return v.EnvVar + " (required when " + v.OtherEnvVar + " is " + v.OtherValue + ")"
+	case "requiredwith":
+		return v.EnvVar + " (required with " + v.OtherEnvVar + ")"
+	default:
+		return v.EnvVar + " (excludes " + v.OtherEnvVar + ")"
+	}
+}
+
+type violation struct {
+	envVar     string
+	constraint string
+}
+
+func (v violation) Error() string {
+	return v.envVar + " (" + v.constraint + ")"
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
@@ -0,0 +1,14 @@
+//go:build testcases
+// +build testcases
+
+package t24
+
+type TestConfigSemantic struct {
+	Port     int    `default:"8080" validate:"port"`
+	Addr     string `default:":8080" validate:"hostport"`
+	Host     string `default:"localhost" validate:"hostname"`
+	Admin    string `optional:"true" validate:"email"`
+	Endpoint string `optional:"true" validate:"absurl"`
+	CertFile string `optional:"true" validate:"filepath-exists"`
+	DataDir  string `optional:"true" validate:"dir-exists"`
+}
@@ -0,0 +1,352 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t24
+
+import (
+	"errors"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	APP_PORT_ENV     = "APP_PORT"
+	APP_ADDR_ENV     = "APP_ADDR"
+	APP_HOST_ENV     = "APP_HOST"
+	APP_ADMIN_ENV    = "APP_ADMIN"
+	APP_ENDPOINT_ENV = "APP_ENDPOINT"
+	APP_CERTFILE_ENV = "APP_CERTFILE"
+	APP_DATADIR_ENV  = "APP_DATADIR"
+)
+
+var (
+	ErrAppPortEnvInvalid = errors.New(APP_PORT_ENV)
+)
+
+// LoadTestConfigSemantic reads the config from the environment.
+func LoadTestConfigSemantic(opts ...LoadOption) (TestConfigSemantic, error) {
+	return LoadTestConfigSemanticFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigSemanticFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigSemanticFromMap(m map[string]string, opts ...LoadOption) (TestConfigSemantic, error) {
+	return LoadTestConfigSemanticFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigSemanticFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigSemanticFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigSemantic, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigSemantic
+	var missingVars []error
+	var formatVars []error
+	var violations []error
+	val_Port, ok := lookup(APP_PORT_ENV)
+	if !ok {
+		val_Port = "8080"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Port", APP_PORT_ENV, ErrAppPortEnvInvalid, val_Port, false, err))
+		} else {
+			config.Port = parsed
+			if !validPortNumber(int64(config.Port)) {
+				violations = append(violations, violation{envVar: APP_PORT_ENV, constraint: "port"})
+			}
+		}
+	}
+	val_Addr, ok := lookup(APP_ADDR_ENV)
+	if !ok {
+		val_Addr = ":8080"
+		ok = true
+	}
+	if ok {
+		config.Addr = val_Addr
+		if !validHostPort(config.Addr) {
+			violations = append(violations, violation{envVar: APP_ADDR_ENV, constraint: "hostport"})
+		}
+	}
+	val_Host, ok := lookup(APP_HOST_ENV)
+	if !ok {
+		val_Host = "localhost"
+		ok = true
+	}
+	if ok {
+		config.Host = val_Host
+		if !validHostname(config.Host) {
+			violations = append(violations, violation{envVar: APP_HOST_ENV, constraint: "hostname"})
+		}
+	}
+	val_Admin, ok := lookup(APP_ADMIN_ENV)
+	if ok {
+		config.Admin = val_Admin
+		if !validEmail(config.Admin) {
+			violations = append(violations, violation{envVar: APP_ADMIN_ENV, constraint: "email"})
+		}
+	}
+	val_Endpoint, ok := lookup(APP_ENDPOINT_ENV)
+	if ok {
+		config.Endpoint = val_Endpoint
+		if !validAbsURL(config.Endpoint) {
+			violations = append(violations, violation{envVar: APP_ENDPOINT_ENV, constraint: "absurl"})
+		}
+	}
+	val_CertFile, ok := lookup(APP_CERTFILE_ENV)
+	if ok {
+		config.CertFile = val_CertFile
+		if !filepathExists(config.CertFile) {
+			violations = append(violations, violation{envVar: APP_CERTFILE_ENV, constraint: "filepath-exists"})
+		}
+	}
+	val_DataDir, ok := lookup(APP_DATADIR_ENV)
+	if ok {
+		config.DataDir = val_DataDir
+		if !dirExists(config.DataDir) {
+			violations = append(violations, violation{envVar: APP_DATADIR_ENV, constraint: "dir-exists"})
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(violations) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(violations) > 0 {
+			verr = errors.Join(verr, ValidationError{vars: violations})
+		}
+		return TestConfigSemantic{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// ValidationError lists the env vars whose values violate the constraints
+// of their fields, each with the constraint that failed.
+type ValidationError struct {
+	vars []error
+}
+
+func (m ValidationError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ValidationError) Error() string {
+	return joinEnvVars("envs ", m.vars, " violate their constraints")
+}
+
+type violation struct {
+	envVar     string
+	constraint string
+}
+
+func (v violation) Error() string {
+	return v.envVar + " (" + v.constraint + ")"
+}
+
+// dirExists reports whether s names an existing directory.
+func dirExists(s string) bool {
+	info, err := os.Stat(s)
+	return err == nil && info.IsDir()
+}
+
+// filepathExists reports whether s names an existing file that is not a
+// directory.
+func filepathExists(s string) bool {
+	info, err := os.Stat(s)
+	return err == nil && !info.IsDir()
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// validAbsURL reports whether s is an absolute URL with a scheme and a host.
+func validAbsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// validEmail reports whether s is a bare email address, without a display
+// name or angle brackets.
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+// validHostPort reports whether s is a host:port address. The host may be
+// a hostname, an IP address or empty, as in the listen address ":8080".
+func validHostPort(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || !validPort(port) {
+		return false
+	}
+	return host == "" || net.ParseIP(host) != nil || validHostname(host)
+}
+
+// validHostname reports whether s is a hostname as described by RFC 1123.
+// A single trailing dot is allowed.
+func validHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validPort reports whether s is a TCP or UDP port number, 1 to 65535.
+func validPort(s string) bool {
+	n, err := strconv.ParseUint(s, 10, 16)
+	return err == nil && n > 0
+}
+
+// validPortNumber reports whether n is a TCP or UDP port number, 1 to 65535.
+func validPortNumber(n int64) bool {
+	return n > 0 && n <= 65535
+}
@@ -0,0 +1,140 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t25
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	APICONFIG_API_PORT_ENV     = "API_PORT"
+	APICONFIG_API_LOGLEVEL_ENV = "API_LOGLEVEL"
+	APICONFIG_APP_REGION_ENV   = "APP_REGION"
+	APICONFIG_API_PROFILE_ENV  = "API_PROFILE"
+)
+
+var (
+	ErrAPIConfigApiPortEnvInvalid = errors.New(APICONFIG_API_PORT_ENV)
+)
+
+// LoadAPIConfig reads the config from the environment.
+func LoadAPIConfig(opts ...LoadOption) (APIConfig, error) {
+	return LoadAPIConfigFrom(os.LookupEnv, opts...)
+}
+
+// LoadAPIConfigFromMap reads the config from m instead of the
+// environment.
+func LoadAPIConfigFromMap(m map[string]string, opts ...LoadOption) (APIConfig, error) {
+	return LoadAPIConfigFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadAPIConfigFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadAPIConfigFrom(lookup func(string) (string, bool), opts ...LoadOption) (APIConfig, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.configFile != "" {
+		return APIConfig{}, errors.New("LoadAPIConfigFrom is generated without JSON file support, WithConfigFile cannot be used")
+	}
+	if options.configDir != "" {
+		return APIConfig{}, errors.New("LoadAPIConfigFrom is generated without config dir support, WithConfigDir cannot be used")
+	}
+	if options.flagSet != nil {
+		return APIConfig{}, errors.New("LoadAPIConfigFrom is generated without flag support, WithFlags cannot be used")
+	}
+	if options.secretResolver != nil || options.secretTimeout != 0 {
+		return APIConfig{}, errors.New("LoadAPIConfigFrom is generated without secret support, WithSecretResolver and WithSecretTimeout cannot be used")
+	}
+	sources := []func(string) (string, bool){lookup}
+	if len(options.dotenvFiles) > 0 {
+		values, err := readDotenvFiles(options.dotenvFiles, false)
+		if err != nil {
+			return APIConfig{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+	lookup = layerLookups(nil, sources...)
+	defaults := map[string]string{
+		APICONFIG_API_PORT_ENV:     "8080",
+		APICONFIG_API_LOGLEVEL_ENV: "info",
+		APICONFIG_APP_REGION_ENV:   "eu",
+	}
+	profile := options.profile
+	if profile == "" {
+		profile, _ = lookup(APICONFIG_API_PROFILE_ENV)
+	}
+	switch profile {
+	case "":
+	case "dev":
+		defaults[APICONFIG_API_LOGLEVEL_ENV] = "debug"
+	default:
+		return APIConfig{}, UnknownProfileError{Profile: profile, Known: []string{"dev"}}
+	}
+
+	var config APIConfig
+	var missingVars []error
+	var formatVars []error
+	var violations []error
+	val_Port, ok := lookup(APICONFIG_API_PORT_ENV)
+	if !ok {
+		val_Port = "8080"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Port)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Port", APICONFIG_API_PORT_ENV, ErrAPIConfigApiPortEnvInvalid, val_Port, false, err))
+		} else {
+			config.Port = parsed
+			if !validPortNumber(int64(config.Port)) {
+				violations = append(violations, violation{envVar: APICONFIG_API_PORT_ENV, constraint: "port"})
+			}
+		}
+	}
+	val_LogLevel, ok := lookup(APICONFIG_API_LOGLEVEL_ENV)
+	if !ok {
+		val_LogLevel, ok = defaults[APICONFIG_API_LOGLEVEL_ENV]
+	}
+	if ok {
+		val_LogLevel = strings.TrimSpace(val_LogLevel)
+		val_LogLevel = strings.ToLower(val_LogLevel)
+		config.LogLevel = val_LogLevel
+	}
+	val_Region, ok := lookup(APICONFIG_APP_REGION_ENV)
+	if !ok {
+		val_Region = "eu"
+		ok = true
+	}
+	if ok {
+		config.Region = val_Region
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(violations) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(violations) > 0 {
+			verr = errors.Join(verr, ValidationError{vars: violations})
+		}
+		return APIConfig{}, verr
+	}
+
+	return config, nil
+}
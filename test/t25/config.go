@@ -0,0 +1,16 @@
+//go:build testcases
+// +build testcases
+
+package t25
+
+type APIConfig struct {
+	Port     int    `default:"8080" validate:"port"`
+	LogLevel string `default:"info" default.dev:"debug" normalize:"trim,lower"`
+	Region   string `env:"APP_REGION" default:"eu"`
+}
+
+type WorkerConfig struct {
+	Queue       string `aliases:"WORKER_QUEUE_NAME"`
+	Concurrency int    `default:"4" min:"1"`
+	Region      string `env:"APP_REGION" default:"eu"`
+}
@@ -0,0 +1,1242 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t25
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning   func(Warning)
+	dotenvFiles []string
+	configFile  string
+	configDir   string
+	flagSet     *flag.FlagSet
+
+	secretResolver SecretResolver
+	secretTimeout  time.Duration
+	profile        string
+	envIndex       envIndex
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+// WithDotenv layers the values of dotenv files under the real environment.
+// Later files take precedence over earlier ones, and files that do not
+// exist are skipped, e.g. WithDotenv(".env", ".env.local").
+func WithDotenv(paths ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenvFiles = append(o.dotenvFiles, paths...)
+	}
+}
+
+// WithConfigFile layers the values of a JSON config file under the real
+// environment and any dotenv files. The document mirrors the nesting of
+// the config struct, keyed by field name, e.g. {"Server": {"Port": 8080}}.
+func WithConfigFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.configFile = path
+	}
+}
+
+// WithConfigDir layers the files of a directory, such as a mounted
+// ConfigMap or Secret, under the real environment. Each file is named after
+// an env var, in its exact, lowercased or dotted form (e.g. app.db.url),
+// and takes precedence over dotenv and JSON config files. The directory is
+// read on every load, so updates to the mount are picked up.
+func WithConfigDir(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.configDir = path
+	}
+}
+
+func withEnvIndex(idx envIndex) LoadOption {
+	return func(o *loadOptions) {
+		o.envIndex = idx
+	}
+}
+
+// WithFlags gives the flags set on fs precedence over every other source.
+// fs must be parsed and its flags registered with the Register...Flags
+// function of the config.
+func WithFlags(fs *flag.FlagSet) LoadOption {
+	return func(o *loadOptions) {
+		o.flagSet = fs
+	}
+}
+
+// WithSecretResolver sets the resolver used for values that are secret
+// references, e.g. secretref://payments/db-password. It defaults to
+// FileSecretResolver{}.
+func WithSecretResolver(r SecretResolver) LoadOption {
+	return func(o *loadOptions) {
+		o.secretResolver = r
+	}
+}
+
+// WithSecretTimeout bounds the time spent resolving all secret references,
+// which are resolved concurrently. It defaults to 10 seconds.
+func WithSecretTimeout(d time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.secretTimeout = d
+	}
+}
+
+const (
+	secretRefScheme      = "secretref://"
+	defaultSecretTimeout = 10 * time.Second
+)
+
+// SecretResolver resolves a secret reference, such as
+// secretref://payments/db-password, into the secret it points to.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, ref string) (string, error)
+}
+
+// FileSecretResolver resolves secretref://path by reading the file at path,
+// relative to Dir if it is set, and trimming trailing newlines. With Dir
+// set, paths that escape it are rejected.
+type FileSecretResolver struct {
+	Dir string
+}
+
+func (r FileSecretResolver) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	path, ok := strings.CutPrefix(ref, secretRefScheme)
+	if !ok || path == "" {
+		return "", errors.New("invalid secret reference " + ref)
+	}
+	if r.Dir != "" {
+		if !filepath.IsLocal(path) {
+			return "", errors.New("secret reference " + ref + " is outside of " + r.Dir)
+		}
+		path = filepath.Join(r.Dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// WithProfile selects the profile whose defaults apply, taking precedence
+// over the <PREFIX>_PROFILE env var.
+func WithProfile(name string) LoadOption {
+	return func(o *loadOptions) {
+		o.profile = name
+	}
+}
+
+// UnknownProfileError is returned when the selected profile has no defaults
+// defined for it.
+type UnknownProfileError struct {
+	Profile string
+	Known   []string
+}
+
+func (e UnknownProfileError) Error() string {
+	return "unknown profile \"" + e.Profile + "\", expected one of " + strings.Join(e.Known, ", ")
+}
+
+// flagValue holds the raw value of a flag, which is parsed together with
+// the other sources of envVar.
+type flagValue struct {
+	envVar string
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// fieldInfo describes a field of a config for FormatConfigError.
+type fieldInfo struct {
+	path       string
+	typ        string // type with an example value, e.g. duration (e.g. 30s)
+	def        string
+	hasDefault bool
+	doc        string
+}
+
+// fieldInfos holds the fields of every config of the package, keyed by env
+// var. Each config adds its fields when the package is initialized.
+var fieldInfos = map[string]fieldInfo{}
+
+// FormatConfigError formats an error returned by a Load function as a table
+// with one row per env var at fault, showing its field, type with an example
+// value, default, what went wrong and the doc comment of the field. Errors
+// that are not about a single env var, such as those of Validate methods,
+// are listed below the table.
+func FormatConfigError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var rows [][2]string // env var and problem
+	var others []string
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case FieldError:
+			if e.Kind == "missing" {
+				rows = append(rows, [2]string{e.EnvVar, "not set"})
+			} else {
+				rows = append(rows, [2]string{e.EnvVar, "invalid value " + strconv.Quote(e.Value) + ": " + e.reason})
+			}
+		case violation:
+			rows = append(rows, [2]string{e.envVar, "violates " + e.constraint})
+		case ConditionViolation:
+			problem := "excludes " + e.OtherEnvVar
+			switch e.Rule {
+			case "requiredif":
+				problem = "required when " + e.OtherEnvVar + " is " + e.OtherValue
+			case "requiredwith":
+				problem = "required with " + e.OtherEnvVar
+			}
+			rows = append(rows, [2]string{e.EnvVar, problem})
+		case cycleError:
+			rows = append(rows, [2]string{e.envVar, "references itself: " + e.chain})
+		case secretError:
+			rows = append(rows, [2]string{e.envVar, "secret " + e.ref + " could not be resolved: " + e.err.Error()})
+		case ConflictingEnvVarsError:
+			for _, v := range e.vars {
+				rows = append(rows, [2]string{v.Error(), "set to different values through its aliases"})
+			}
+		case AmbiguousEnvVarsError:
+			for _, v := range e.vars {
+				rows = append(rows, [2]string{v.Error(), "set more than once with different case"})
+			}
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		default:
+			others = append(others, err.Error())
+		}
+	}
+	walk(err)
+
+	var table strings.Builder
+	if len(rows) > 0 {
+		w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		w.Write([]byte("ENV VAR\tFIELD\tTYPE\tDEFAULT\tPROBLEM\tDESCRIPTION\n"))
+		for _, row := range rows {
+			info, ok := fieldInfos[row[0]]
+			if !ok {
+				info = fieldInfo{path: "-", typ: "-"}
+			}
+			def := "-"
+			if info.hasDefault {
+				def = strconv.Quote(info.def)
+			}
+			w.Write([]byte(strings.Join([]string{row[0], info.path, info.typ, def, row[1], info.doc}, "\t") + "\n"))
+		}
+		w.Flush()
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+		if line = strings.TrimRight(line, " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(append(lines, others...), "\n")
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// ValidationError lists the env vars whose values violate the constraints
+// of their fields, each with the constraint that failed.
+type ValidationError struct {
+	vars []error
+}
+
+func (m ValidationError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ValidationError) Error() string {
+	return joinEnvVars("envs ", m.vars, " violate their constraints")
+}
+
+// ConditionViolation is a violated requiredif, requiredwith or excludes tag.
+// It names both fields and their env vars.
+type ConditionViolation struct {
+	Rule        string // requiredif, requiredwith or excludes
+	Field       string
+	EnvVar      string
+	OtherField  string
+	OtherEnvVar string
+	OtherValue  string // value of OtherField that makes Field required, for requiredif
+}
+
+func (v ConditionViolation) Error() string {
+	switch v.Rule {
+	case "requiredif":
+		return v.EnvVar + " (required when " + v.OtherEnvVar + " is " + v.OtherValue + ")"
+	case "requiredwith":
+		return v.EnvVar + " (required with " + v.OtherEnvVar + ")"
+	default:
+		return v.EnvVar + " (excludes " + v.OtherEnvVar + ")"
+	}
+}
+
+type violation struct {
+	envVar     string
+	constraint string
+}
+
+func (v violation) Error() string {
+	return v.envVar + " (" + v.constraint + ")"
+}
+
+// SectionError is an error returned by the Validate method of a section of
+// the config, labelled with the field path of the section.
+type SectionError struct {
+	Config  string // name of the config struct
+	Section string // empty for the config struct itself
+	Err     error
+}
+
+func (e SectionError) Error() string {
+	if e.Section == "" {
+		return e.Config + ": " + e.Err.Error()
+	}
+	return e.Section + ": " + e.Err.Error()
+}
+
+func (e SectionError) Unwrap() error {
+	return e.Err
+}
+
+// CyclicEnvVarsError lists the env vars whose values or defaults reference
+// themselves through ${...}, together with the chain of references.
+type CyclicEnvVarsError struct {
+	vars []error
+}
+
+func (m CyclicEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m CyclicEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " reference themselves")
+}
+
+// UnresolvedSecretsError lists the env vars whose secret references could
+// not be resolved. Each of them unwraps to the error of the resolver.
+type UnresolvedSecretsError struct {
+	vars []error
+}
+
+func (m UnresolvedSecretsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m UnresolvedSecretsError) Error() string {
+	return joinEnvVars("secrets of envs ", m.vars, " could not be resolved")
+}
+
+type secretError struct {
+	envVar string
+	ref    string
+	err    error
+}
+
+func (e secretError) Error() string {
+	return e.envVar + " (" + e.ref + ": " + e.err.Error() + ")"
+}
+
+func (e secretError) Unwrap() error {
+	return e.err
+}
+
+type ConflictingEnvVarsError struct {
+	vars []error
+}
+
+func (m ConflictingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ConflictingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are set to different values through their aliases")
+}
+
+type AmbiguousEnvVarsError struct {
+	vars []error
+}
+
+func (m AmbiguousEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m AmbiguousEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are set more than once with different case")
+}
+
+// dirExists reports whether s names an existing directory.
+func dirExists(s string) bool {
+	info, err := os.Stat(s)
+	return err == nil && info.IsDir()
+}
+
+// filepathExists reports whether s names an existing file that is not a
+// directory.
+func filepathExists(s string) bool {
+	info, err := os.Stat(s)
+	return err == nil && !info.IsDir()
+}
+
+// layerLookups combines sources, earlier ones taking precedence. The names of
+// each group in aliases belong to one field and are all read from the first
+// source that sets any of them, so that a source wins outright instead of
+// mixing its value with the aliases set in the sources below it.
+func layerLookups(aliases [][]string, sources ...func(string) (string, bool)) func(string) (string, bool) {
+	groups := map[string][]string{}
+	for _, names := range aliases {
+		for _, name := range names {
+			groups[name] = names
+		}
+	}
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			if value, ok := source(name); ok {
+				return value, true
+			}
+			for _, alias := range groups[name] {
+				if alias == name {
+					continue
+				}
+				if _, ok := source(alias); ok {
+					return "", false
+				}
+			}
+		}
+		return "", false
+	}
+}
+
+// lookupEnvWithAliases returns the value of the first of names that is set
+// and the name it was read from. It also reports whether two of the names
+// are set to different values. Unless unset is nil, the values it reports
+// true for are skipped as if the name was not set.
+func lookupEnvWithAliases(lookup func(string) (string, bool), unset func(string) bool, names ...string) (value string, from string, ok bool, conflict bool) {
+	for _, name := range names {
+		v, set := lookup(name)
+		if !set || unset != nil && unset(v) {
+			continue
+		}
+		if !ok {
+			value, from, ok = v, name, true
+		} else if v != value {
+			conflict = true
+		}
+	}
+	return value, from, ok, conflict
+}
+
+// envIndex resolves env var names regardless of case. It maps upper-cased
+// names to every variable set under that name.
+type envIndex map[string][]envEntry
+
+type envEntry struct {
+	name  string
+	value string
+}
+
+func newEnvIndex(environ []string) envIndex {
+	idx := envIndex{}
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if name == "" {
+			continue
+		}
+		idx.add(name, value)
+	}
+	return idx
+}
+
+func newEnvIndexFromMap(m map[string]string) envIndex {
+	idx := envIndex{}
+	for name, value := range m {
+		idx.add(name, value)
+	}
+	return idx
+}
+
+func (idx envIndex) add(name, value string) {
+	key := strings.ToUpper(name)
+	idx[key] = append(idx[key], envEntry{name: name, value: value})
+}
+
+// lookup returns the value of the variable matching name regardless of
+// case, preferring an exact match.
+func (idx envIndex) lookup(name string) (string, bool) {
+	entries := idx[strings.ToUpper(name)]
+	for _, e := range entries {
+		if e.name == name {
+			return e.value, true
+		}
+	}
+	if len(entries) > 0 {
+		return entries[0].value, true
+	}
+	return "", false
+}
+
+// ambiguous reports whether any of names is set more than once with
+// different case.
+func (idx envIndex) ambiguous(names ...string) bool {
+	for _, name := range names {
+		if len(idx[strings.ToUpper(name)]) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// expander expands ${VAR} and ${VAR:-fallback} in values and defaults, with
+// $$ standing for a literal $. The fallback is used when VAR is unset or
+// empty. Referenced variables are expanded in turn, falling back to their
+// defaults, and cycles are recorded instead of recursing forever.
+type expander struct {
+	lookup   func(string) (string, bool)
+	defaults map[string]string
+	active   []string
+	cycles   []error
+	reported map[string]bool
+}
+
+func newExpander(lookup func(string) (string, bool), defaults map[string]string) *expander {
+	return &expander{lookup: lookup, defaults: defaults, reported: map[string]bool{}}
+}
+
+// lookupExpanded has the same contract as os.LookupEnv and expands the
+// value of name.
+func (e *expander) lookupExpanded(name string) (string, bool) {
+	raw, ok := e.lookup(name)
+	if !ok {
+		return "", false
+	}
+	return e.expandValueOf(name, raw), true
+}
+
+func (e *expander) expandDefault(name string) string {
+	return e.expandValueOf(name, e.defaults[name])
+}
+
+// value returns the expanded value of a referenced variable, or of its
+// default if it is not set.
+func (e *expander) value(name string) (string, bool) {
+	raw, ok := e.lookup(name)
+	if !ok {
+		raw, ok = e.defaults[name]
+	}
+	if !ok {
+		return "", false
+	}
+	return e.expandValueOf(name, raw), true
+}
+
+func (e *expander) expandValueOf(name string, raw string) string {
+	for i, active := range e.active {
+		if active != name {
+			continue
+		}
+		root := e.active[0]
+		if !e.reported[root] {
+			e.reported[root] = true
+			chain := strings.Join(append(e.active[i:len(e.active):len(e.active)], name), " -> ")
+			e.cycles = append(e.cycles, cycleError{envVar: root, chain: chain})
+		}
+		return ""
+	}
+	if !strings.Contains(raw, "$") {
+		return raw
+	}
+	e.active = append(e.active, name)
+	defer func() {
+		e.active = e.active[:len(e.active)-1]
+	}()
+	return e.expand(raw)
+}
+
+func (e *expander) expand(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end, depth := -1, 0
+			for j := i + 1; j < len(s) && end < 0; j++ {
+				switch s[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+					if depth == 0 {
+						end = j
+					}
+				}
+			}
+			if end < 0 {
+				// an unterminated reference is kept as it is
+				sb.WriteString(s[i:])
+				return sb.String()
+			}
+			name, fallback, hasFallback := strings.Cut(s[i+2:end], ":-")
+			value, ok := e.value(name)
+			if hasFallback && (!ok || value == "") {
+				value = e.expand(fallback)
+			}
+			sb.WriteString(value)
+			i = end
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String()
+}
+
+type cycleError struct {
+	envVar string
+	chain  string
+}
+
+func (e cycleError) Error() string {
+	return e.envVar + " (" + e.chain + ")"
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// parseExtendedDuration accepts everything time.ParseDuration does, plus
+// the units "d" (24h) and "w" (7d), e.g. "1w2d12h".
+func parseExtendedDuration(s string) (time.Duration, error) {
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, errors.New("invalid duration " + strconv.Quote(orig))
+	}
+	var d time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && (s[i] == '.' || ('0' <= s[i] && s[i] <= '9')) {
+			i++
+		}
+		j := i
+		for j < len(s) && s[j] != '.' && (s[j] < '0' || s[j] > '9') {
+			j++
+		}
+		num, unit := s[:i], s[i:j]
+		s = s[j:]
+		if num == "" || unit == "" {
+			return 0, errors.New("invalid duration " + strconv.Quote(orig))
+		}
+		var part time.Duration
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig))
+			}
+			u := 24 * time.Hour
+			if unit == "w" {
+				u = 7 * 24 * time.Hour
+			}
+			v := math.Round(f * float64(u))
+			if v >= math.MaxInt64 {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig) + ": out of range")
+			}
+			part = time.Duration(v)
+		default:
+			p, err := time.ParseDuration(num + unit)
+			if err != nil {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig))
+			}
+			part = p
+		}
+		if d > math.MaxInt64-part {
+			return 0, errors.New("invalid duration " + strconv.Quote(orig) + ": out of range")
+		}
+		d += part
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseISO8601Duration parses ISO 8601 durations such as "P7D" or
+// "PT1H30M". Years and months have no fixed length and are rejected.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	invalid := errors.New("invalid ISO 8601 duration " + strconv.Quote(s))
+	rest := strings.ToUpper(s)
+	neg := false
+	if rest != "" && (rest[0] == '-' || rest[0] == '+') {
+		neg = rest[0] == '-'
+		rest = rest[1:]
+	}
+	if rest == "" || rest[0] != 'P' {
+		return 0, invalid
+	}
+	rest = rest[1:]
+	var d time.Duration
+	inTime, seen := false, false
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime || len(rest) == 1 {
+				return 0, invalid
+			}
+			inTime = true
+			rest = rest[1:]
+			continue
+		}
+		i := 0
+		for i < len(rest) && (rest[i] == '.' || rest[i] == ',' || ('0' <= rest[i] && rest[i] <= '9')) {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return 0, invalid
+		}
+		f, err := strconv.ParseFloat(strings.Replace(rest[:i], ",", ".", 1), 64)
+		if err != nil {
+			return 0, invalid
+		}
+		designator := rest[i]
+		rest = rest[i+1:]
+		var unit time.Duration
+		switch {
+		case !inTime && designator == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && designator == 'D':
+			unit = 24 * time.Hour
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, errors.New("invalid ISO 8601 duration " + strconv.Quote(s) + ": years and months have no fixed length")
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		default:
+			return 0, invalid
+		}
+		v := math.Round(f * float64(unit))
+		if v >= math.MaxInt64 || d > math.MaxInt64-time.Duration(v) {
+			return 0, errors.New("invalid ISO 8601 duration " + strconv.Quote(s) + ": out of range")
+		}
+		d += time.Duration(v)
+		seen = true
+	}
+	if !seen {
+		return 0, invalid
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// readConfigDir reads a directory with one file per variable, such as a
+// mounted ConfigMap or Secret. A variable is read from the file named after
+// it, its lowercased form or its lowercased form with dots as separators,
+// e.g. APP_DB_URL, app_db_url or app.db.url. Trailing newlines are trimmed,
+// and a directory that does not exist is skipped.
+func readConfigDir(dir string, names []string) (map[string]string, error) {
+	values := map[string]string{}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		dotted := strings.NewReplacer("_", ".", "-", ".").Replace(lower)
+		for _, file := range []string{name, lower, dotted} {
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[name] = strings.TrimRight(string(data), "\r\n")
+			break
+		}
+	}
+	return values, nil
+}
+
+// readConfigFile reads a JSON config file and returns its values keyed by
+// env var. paths maps the dotted path of every field to its env var; other
+// keys are rejected so that typos do not go unnoticed. Values are kept as
+// text and parsed like the environment, and null leaves a field unset.
+func readConfigFile(path string, paths map[string]string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	values := map[string]string{}
+	if err := flattenConfigFile(path, "", doc, paths, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenConfigFile(file string, prefix string, node map[string]any, paths map[string]string, values map[string]string) error {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fieldPath := key
+		if prefix != "" {
+			fieldPath = prefix + "." + key
+		}
+		if section, ok := node[key].(map[string]any); ok {
+			if err := flattenConfigFile(file, fieldPath, section, paths, values); err != nil {
+				return err
+			}
+			continue
+		}
+		name, ok := paths[fieldPath]
+		if !ok {
+			return errors.New(file + ": unknown field " + fieldPath)
+		}
+		switch v := node[key].(type) {
+		case nil:
+		case string:
+			values[name] = v
+		case json.Number:
+			values[name] = v.String()
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		default:
+			return errors.New(file + ": field " + fieldPath + " must be a string, number or boolean")
+		}
+	}
+	return nil
+}
+
+// readDotenvFiles reads and merges dotenv files, later files taking
+// precedence. Files that do not exist are skipped. escapeDollar is passed
+// on to parseDotenv.
+func readDotenvFiles(paths []string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := parseDotenv(path, string(data), escapeDollar)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// parseDotenv parses the contents of a dotenv file. It supports comments,
+// an optional export prefix, single quoted values (taken literally), double
+// quoted values (with \n, \r, \t, \", \\ and \$ escapes, possibly spanning
+// several lines) and unquoted values, which end at an inline " #" comment.
+// With escapeDollar, the literal dollars of \$ escapes and single quoted
+// values are written as $$, so that interpolation keeps them as they are.
+func parseDotenv(path string, data string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	line := 1
+	fail := func(msg string) error {
+		return errors.New(path + ":" + strconv.Itoa(line) + ": " + msg)
+	}
+	lineEnd := func(i int) int {
+		if end := strings.IndexByte(data[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(data)
+	}
+	i := 0
+	for i < len(data) {
+		switch data[i] {
+		case '\n':
+			line++
+			i++
+			continue
+		case ' ', '\t', '\r':
+			i++
+			continue
+		case '#':
+			i = lineEnd(i)
+			continue
+		}
+
+		end := lineEnd(i)
+		if rest, ok := strings.CutPrefix(data[i:end], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			i += len("export")
+		}
+		eq := strings.IndexByte(data[i:end], '=')
+		if eq < 0 {
+			return nil, fail("expected KEY=value")
+		}
+		key := strings.TrimSpace(data[i : i+eq])
+		if key == "" || strings.ContainsAny(key, " \t'\"#") {
+			return nil, fail("invalid key " + strconv.Quote(key))
+		}
+		i += eq + 1
+		for i < end && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+
+		if i < end && (data[i] == '\'' || data[i] == '"') {
+			quote := data[i]
+			i++
+			startLine := line
+			var sb strings.Builder
+			closed := false
+			for i < len(data) {
+				c := data[i]
+				if c == quote {
+					closed = true
+					i++
+					break
+				}
+				if quote == '"' && c == '\\' && i+1 < len(data) {
+					switch next := data[i+1]; next {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					case '$':
+						if escapeDollar {
+							sb.WriteByte('$')
+						}
+						sb.WriteByte('$')
+					case '"', '\\':
+						sb.WriteByte(next)
+					default:
+						sb.WriteByte(c)
+						sb.WriteByte(next)
+						if next == '\n' {
+							line++
+						}
+					}
+					i += 2
+					continue
+				}
+				if c == '\n' {
+					line++
+				}
+				if quote == '\'' && c == '$' && escapeDollar {
+					sb.WriteByte('$')
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				line = startLine
+				return nil, fail("unterminated quoted value for " + key)
+			}
+			end = lineEnd(i)
+			if rest := strings.TrimSpace(data[i:end]); rest != "" && rest[0] != '#' {
+				return nil, fail("unexpected characters after quoted value for " + key)
+			}
+			values[key] = sb.String()
+		} else {
+			raw := data[i:end]
+			if comment := strings.Index(raw, " #"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			if comment := strings.Index(raw, "\t#"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			values[key] = strings.TrimSpace(raw)
+		}
+		i = end
+	}
+	return values, nil
+}
+
+// resolveSecrets looks up names once and returns the values of those that
+// are set, with secret references resolved concurrently and within timeout,
+// and the names whose values were secret references.
+// Resolvers that ignore the context are abandoned once the timeout expires.
+func resolveSecrets(resolver SecretResolver, timeout time.Duration, lookup func(string) (string, bool), names []string) (map[string]string, map[string]bool, error) {
+	values := map[string]string{}
+	secrets := map[string]bool{}
+	var pending []string
+	for _, name := range names {
+		if _, seen := values[name]; seen {
+			continue
+		}
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		values[name] = value
+		if strings.HasPrefix(value, secretRefScheme) {
+			pending = append(pending, name)
+			secrets[name] = true
+		}
+	}
+	if len(pending) == 0 {
+		return values, secrets, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	type result struct {
+		value string
+		err   error
+	}
+	results := make([]chan result, len(pending))
+	for i, name := range pending {
+		results[i] = make(chan result, 1)
+		go func(ch chan<- result, ref string) {
+			value, err := resolver.ResolveSecret(ctx, ref)
+			ch <- result{value: value, err: err}
+		}(results[i], values[name])
+	}
+
+	var errs []error
+	for i, name := range pending {
+		var r result
+		select {
+		case r = <-results[i]:
+		case <-ctx.Done():
+			select {
+			case r = <-results[i]:
+			default:
+				r.err = ctx.Err()
+			}
+		}
+		if r.err != nil {
+			errs = append(errs, secretError{envVar: name, ref: values[name], err: r.err})
+		} else {
+			values[name] = r.value
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, UnresolvedSecretsError{vars: errs}
+	}
+	return values, secrets, nil
+}
+
+// validAbsURL reports whether s is an absolute URL with a scheme and a host.
+func validAbsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// validEmail reports whether s is a bare email address, without a display
+// name or angle brackets.
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+// validHostPort reports whether s is a host:port address. The host may be
+// a hostname, an IP address or empty, as in the listen address ":8080".
+func validHostPort(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || !validPort(port) {
+		return false
+	}
+	return host == "" || net.ParseIP(host) != nil || validHostname(host)
+}
+
+// validHostname reports whether s is a hostname as described by RFC 1123.
+// A single trailing dot is allowed.
+func validHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validPort reports whether s is a TCP or UDP port number, 1 to 65535.
+func validPort(s string) bool {
+	n, err := strconv.ParseUint(s, 10, 16)
+	return err == nil && n > 0
+}
+
+// validPortNumber reports whether n is a TCP or UDP port number, 1 to 65535.
+func validPortNumber(n int64) bool {
+	return n > 0 && n <= 65535
+}
@@ -0,0 +1,148 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t25
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+const (
+	WORKERCONFIG_WORKER_QUEUE_ENV       = "WORKER_QUEUE"
+	WORKERCONFIG_WORKER_CONCURRENCY_ENV = "WORKER_CONCURRENCY"
+	WORKERCONFIG_APP_REGION_ENV         = "APP_REGION"
+)
+
+var (
+	ErrWorkerConfigWorkerQueueEnvMissing         = errors.New(WORKERCONFIG_WORKER_QUEUE_ENV)
+	ErrWorkerConfigWorkerQueueEnvConflict        = errors.New(WORKERCONFIG_WORKER_QUEUE_ENV)
+	ErrWorkerConfigWorkerQueueEnvAmbiguous       = errors.New(WORKERCONFIG_WORKER_QUEUE_ENV)
+	ErrWorkerConfigWorkerConcurrencyEnvInvalid   = errors.New(WORKERCONFIG_WORKER_CONCURRENCY_ENV)
+	ErrWorkerConfigWorkerConcurrencyEnvAmbiguous = errors.New(WORKERCONFIG_WORKER_CONCURRENCY_ENV)
+	ErrWorkerConfigAppRegionEnvAmbiguous         = errors.New(WORKERCONFIG_APP_REGION_ENV)
+)
+
+// LoadWorkerConfig reads the config from the environment.
+func LoadWorkerConfig(opts ...LoadOption) (WorkerConfig, error) {
+	opts = append([]LoadOption{withEnvIndex(newEnvIndex(os.Environ()))}, opts...)
+	return LoadWorkerConfigFrom(os.LookupEnv, opts...)
+}
+
+// LoadWorkerConfigFromMap reads the config from m instead of the
+// environment.
+func LoadWorkerConfigFromMap(m map[string]string, opts ...LoadOption) (WorkerConfig, error) {
+	opts = append([]LoadOption{withEnvIndex(newEnvIndexFromMap(m))}, opts...)
+	return LoadWorkerConfigFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadWorkerConfigFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+//
+// Since lookup cannot be enumerated, names are passed to it as they are and
+// it is responsible for matching them regardless of case.
+func LoadWorkerConfigFrom(lookup func(string) (string, bool), opts ...LoadOption) (WorkerConfig, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if len(options.dotenvFiles) > 0 {
+		return WorkerConfig{}, errors.New("LoadWorkerConfigFrom is generated without dotenv support, WithDotenv cannot be used")
+	}
+	if options.configFile != "" {
+		return WorkerConfig{}, errors.New("LoadWorkerConfigFrom is generated without JSON file support, WithConfigFile cannot be used")
+	}
+	if options.configDir != "" {
+		return WorkerConfig{}, errors.New("LoadWorkerConfigFrom is generated without config dir support, WithConfigDir cannot be used")
+	}
+	if options.flagSet != nil {
+		return WorkerConfig{}, errors.New("LoadWorkerConfigFrom is generated without flag support, WithFlags cannot be used")
+	}
+	if options.secretResolver != nil || options.secretTimeout != 0 {
+		return WorkerConfig{}, errors.New("LoadWorkerConfigFrom is generated without secret support, WithSecretResolver and WithSecretTimeout cannot be used")
+	}
+	if options.profile != "" {
+		return WorkerConfig{}, errors.New("LoadWorkerConfigFrom is generated without profiles, WithProfile cannot be used")
+	}
+	if options.envIndex != nil {
+		lookup = options.envIndex.lookup
+	}
+
+	var config WorkerConfig
+	var missingVars []error
+	var formatVars []error
+	var violations []error
+	var conflictVars []error
+	var ambiguousVars []error
+	if options.envIndex.ambiguous(WORKERCONFIG_WORKER_QUEUE_ENV, "WORKER_QUEUE_NAME") {
+		ambiguousVars = append(ambiguousVars, ErrWorkerConfigWorkerQueueEnvAmbiguous)
+	}
+	val_Queue, from, ok, conflict := lookupEnvWithAliases(lookup, nil, WORKERCONFIG_WORKER_QUEUE_ENV, "WORKER_QUEUE_NAME")
+	if conflict {
+		conflictVars = append(conflictVars, ErrWorkerConfigWorkerQueueEnvConflict)
+	} else if ok && from != WORKERCONFIG_WORKER_QUEUE_ENV {
+		options.warn(Warning{EnvVar: WORKERCONFIG_WORKER_QUEUE_ENV, Alias: from})
+	}
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Queue", EnvVar: WORKERCONFIG_WORKER_QUEUE_ENV, Kind: "missing", sentinel: ErrWorkerConfigWorkerQueueEnvMissing})
+	} else {
+		config.Queue = val_Queue
+	}
+	if options.envIndex.ambiguous(WORKERCONFIG_WORKER_CONCURRENCY_ENV) {
+		ambiguousVars = append(ambiguousVars, ErrWorkerConfigWorkerConcurrencyEnvAmbiguous)
+	}
+	val_Concurrency, ok := lookup(WORKERCONFIG_WORKER_CONCURRENCY_ENV)
+	if !ok {
+		val_Concurrency = "4"
+		ok = true
+	}
+	if ok {
+		parsed, err := strconv.Atoi(val_Concurrency)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Concurrency", WORKERCONFIG_WORKER_CONCURRENCY_ENV, ErrWorkerConfigWorkerConcurrencyEnvInvalid, val_Concurrency, false, err))
+		} else {
+			config.Concurrency = parsed
+			if config.Concurrency < 1 {
+				violations = append(violations, violation{envVar: WORKERCONFIG_WORKER_CONCURRENCY_ENV, constraint: "min 1"})
+			}
+		}
+	}
+	if options.envIndex.ambiguous(WORKERCONFIG_APP_REGION_ENV) {
+		ambiguousVars = append(ambiguousVars, ErrWorkerConfigAppRegionEnvAmbiguous)
+	}
+	val_Region, ok := lookup(WORKERCONFIG_APP_REGION_ENV)
+	if !ok {
+		val_Region = "eu"
+		ok = true
+	}
+	if ok {
+		config.Region = val_Region
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(violations) > 0 || len(conflictVars) > 0 || len(ambiguousVars) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(violations) > 0 {
+			verr = errors.Join(verr, ValidationError{vars: violations})
+		}
+		if len(conflictVars) > 0 {
+			verr = errors.Join(verr, ConflictingEnvVarsError{vars: conflictVars})
+		}
+		if len(ambiguousVars) > 0 {
+			verr = errors.Join(verr, AmbiguousEnvVarsError{vars: ambiguousVars})
+		}
+		return WorkerConfig{}, verr
+	}
+
+	return config, nil
+}
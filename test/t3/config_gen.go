@@ -29,50 +29,71 @@ var (
 	ErrTestconfigintsInt64valEnvInvalid = errors.New(TESTCONFIGINTS_INT64VAL_ENV)
 )
 
-func LoadTestConfigInts() (TestConfigInts, error) {
+// LoadTestConfigInts reads the config from the environment.
+func LoadTestConfigInts(opts ...LoadOption) (TestConfigInts, error) {
+	return LoadTestConfigIntsFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigIntsFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigIntsFromMap(m map[string]string, opts ...LoadOption) (TestConfigInts, error) {
+	return LoadTestConfigIntsFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigIntsFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigIntsFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigInts, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var config TestConfigInts
 	var missingVars []error
 	var formatVars []error
-	val_Int8Val, ok := os.LookupEnv(TESTCONFIGINTS_INT8VAL_ENV)
+	val_Int8Val, ok := lookup(TESTCONFIGINTS_INT8VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigintsInt8valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Int8Val", EnvVar: TESTCONFIGINTS_INT8VAL_ENV, Kind: "missing", sentinel: ErrTestconfigintsInt8valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseInt(val_Int8Val, 10, 8)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigintsInt8valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Int8Val", TESTCONFIGINTS_INT8VAL_ENV, ErrTestconfigintsInt8valEnvInvalid, val_Int8Val, false, err))
 		} else {
 			config.Int8Val = int8(parsed)
 		}
 	}
-	val_Int16Val, ok := os.LookupEnv(TESTCONFIGINTS_INT16VAL_ENV)
+	val_Int16Val, ok := lookup(TESTCONFIGINTS_INT16VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigintsInt16valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Int16Val", EnvVar: TESTCONFIGINTS_INT16VAL_ENV, Kind: "missing", sentinel: ErrTestconfigintsInt16valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseInt(val_Int16Val, 10, 16)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigintsInt16valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Int16Val", TESTCONFIGINTS_INT16VAL_ENV, ErrTestconfigintsInt16valEnvInvalid, val_Int16Val, false, err))
 		} else {
 			config.Int16Val = int16(parsed)
 		}
 	}
-	val_Int32Val, ok := os.LookupEnv(TESTCONFIGINTS_INT32VAL_ENV)
+	val_Int32Val, ok := lookup(TESTCONFIGINTS_INT32VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigintsInt32valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Int32Val", EnvVar: TESTCONFIGINTS_INT32VAL_ENV, Kind: "missing", sentinel: ErrTestconfigintsInt32valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseInt(val_Int32Val, 10, 32)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigintsInt32valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Int32Val", TESTCONFIGINTS_INT32VAL_ENV, ErrTestconfigintsInt32valEnvInvalid, val_Int32Val, false, err))
 		} else {
 			config.Int32Val = int32(parsed)
 		}
 	}
-	val_Int64Val, ok := os.LookupEnv(TESTCONFIGINTS_INT64VAL_ENV)
+	val_Int64Val, ok := lookup(TESTCONFIGINTS_INT64VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigintsInt64valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Int64Val", EnvVar: TESTCONFIGINTS_INT64VAL_ENV, Kind: "missing", sentinel: ErrTestconfigintsInt64valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseInt(val_Int64Val, 10, 64)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigintsInt64valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Int64Val", TESTCONFIGINTS_INT64VAL_ENV, ErrTestconfigintsInt64valEnvInvalid, val_Int64Val, false, err))
 		} else {
 			config.Int64Val = int64(parsed)
 		}
@@ -92,6 +113,79 @@ func LoadTestConfigInts() (TestConfigInts, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -101,14 +195,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -120,12 +207,31 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return fe
 }
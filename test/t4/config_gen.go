@@ -29,50 +29,71 @@ var (
 	ErrTestconfiguintsUint64valEnvInvalid = errors.New(TESTCONFIGUINTS_UINT64VAL_ENV)
 )
 
-func LoadTestConfigUints() (TestConfigUints, error) {
+// LoadTestConfigUints reads the config from the environment.
+func LoadTestConfigUints(opts ...LoadOption) (TestConfigUints, error) {
+	return LoadTestConfigUintsFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigUintsFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigUintsFromMap(m map[string]string, opts ...LoadOption) (TestConfigUints, error) {
+	return LoadTestConfigUintsFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigUintsFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigUintsFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigUints, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var config TestConfigUints
 	var missingVars []error
 	var formatVars []error
-	val_Uint8Val, ok := os.LookupEnv(TESTCONFIGUINTS_UINT8VAL_ENV)
+	val_Uint8Val, ok := lookup(TESTCONFIGUINTS_UINT8VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfiguintsUint8valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Uint8Val", EnvVar: TESTCONFIGUINTS_UINT8VAL_ENV, Kind: "missing", sentinel: ErrTestconfiguintsUint8valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseUint(val_Uint8Val, 10, 8)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfiguintsUint8valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Uint8Val", TESTCONFIGUINTS_UINT8VAL_ENV, ErrTestconfiguintsUint8valEnvInvalid, val_Uint8Val, false, err))
 		} else {
 			config.Uint8Val = uint8(parsed)
 		}
 	}
-	val_Uint16Val, ok := os.LookupEnv(TESTCONFIGUINTS_UINT16VAL_ENV)
+	val_Uint16Val, ok := lookup(TESTCONFIGUINTS_UINT16VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfiguintsUint16valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Uint16Val", EnvVar: TESTCONFIGUINTS_UINT16VAL_ENV, Kind: "missing", sentinel: ErrTestconfiguintsUint16valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseUint(val_Uint16Val, 10, 16)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfiguintsUint16valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Uint16Val", TESTCONFIGUINTS_UINT16VAL_ENV, ErrTestconfiguintsUint16valEnvInvalid, val_Uint16Val, false, err))
 		} else {
 			config.Uint16Val = uint16(parsed)
 		}
 	}
-	val_Uint32Val, ok := os.LookupEnv(TESTCONFIGUINTS_UINT32VAL_ENV)
+	val_Uint32Val, ok := lookup(TESTCONFIGUINTS_UINT32VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfiguintsUint32valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Uint32Val", EnvVar: TESTCONFIGUINTS_UINT32VAL_ENV, Kind: "missing", sentinel: ErrTestconfiguintsUint32valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseUint(val_Uint32Val, 10, 32)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfiguintsUint32valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Uint32Val", TESTCONFIGUINTS_UINT32VAL_ENV, ErrTestconfiguintsUint32valEnvInvalid, val_Uint32Val, false, err))
 		} else {
 			config.Uint32Val = uint32(parsed)
 		}
 	}
-	val_Uint64Val, ok := os.LookupEnv(TESTCONFIGUINTS_UINT64VAL_ENV)
+	val_Uint64Val, ok := lookup(TESTCONFIGUINTS_UINT64VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfiguintsUint64valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Uint64Val", EnvVar: TESTCONFIGUINTS_UINT64VAL_ENV, Kind: "missing", sentinel: ErrTestconfiguintsUint64valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseUint(val_Uint64Val, 10, 64)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfiguintsUint64valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Uint64Val", TESTCONFIGUINTS_UINT64VAL_ENV, ErrTestconfiguintsUint64valEnvInvalid, val_Uint64Val, false, err))
 		} else {
 			config.Uint64Val = uint64(parsed)
 		}
@@ -92,6 +113,79 @@ func LoadTestConfigUints() (TestConfigUints, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -101,14 +195,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -120,12 +207,31 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return fe
 }
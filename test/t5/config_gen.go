@@ -23,28 +23,49 @@ var (
 	ErrTestconfigfloatsFloat64valEnvInvalid = errors.New(TESTCONFIGFLOATS_FLOAT64VAL_ENV)
 )
 
-func LoadTestConfigFloats() (TestConfigFloats, error) {
+// LoadTestConfigFloats reads the config from the environment.
+func LoadTestConfigFloats(opts ...LoadOption) (TestConfigFloats, error) {
+	return LoadTestConfigFloatsFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigFloatsFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigFloatsFromMap(m map[string]string, opts ...LoadOption) (TestConfigFloats, error) {
+	return LoadTestConfigFloatsFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigFloatsFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigFloatsFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigFloats, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var config TestConfigFloats
 	var missingVars []error
 	var formatVars []error
-	val_Float32Val, ok := os.LookupEnv(TESTCONFIGFLOATS_FLOAT32VAL_ENV)
+	val_Float32Val, ok := lookup(TESTCONFIGFLOATS_FLOAT32VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigfloatsFloat32valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Float32Val", EnvVar: TESTCONFIGFLOATS_FLOAT32VAL_ENV, Kind: "missing", sentinel: ErrTestconfigfloatsFloat32valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseFloat(val_Float32Val, 32)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigfloatsFloat32valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Float32Val", TESTCONFIGFLOATS_FLOAT32VAL_ENV, ErrTestconfigfloatsFloat32valEnvInvalid, val_Float32Val, false, err))
 		} else {
 			config.Float32Val = float32(parsed)
 		}
 	}
-	val_Float64Val, ok := os.LookupEnv(TESTCONFIGFLOATS_FLOAT64VAL_ENV)
+	val_Float64Val, ok := lookup(TESTCONFIGFLOATS_FLOAT64VAL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigfloatsFloat64valEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Float64Val", EnvVar: TESTCONFIGFLOATS_FLOAT64VAL_ENV, Kind: "missing", sentinel: ErrTestconfigfloatsFloat64valEnvMissing})
 	} else {
 		parsed, err := strconv.ParseFloat(val_Float64Val, 64)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigfloatsFloat64valEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Float64Val", TESTCONFIGFLOATS_FLOAT64VAL_ENV, ErrTestconfigfloatsFloat64valEnvInvalid, val_Float64Val, false, err))
 		} else {
 			config.Float64Val = parsed
 		}
@@ -64,6 +85,79 @@ func LoadTestConfigFloats() (TestConfigFloats, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -73,14 +167,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -92,12 +179,31 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return fe
 }
@@ -5,8 +5,11 @@
 package t6
 
 import (
+	"encoding/json"
 	"errors"
+	"flag"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -24,29 +27,101 @@ var (
 	ErrTestconfignestedNestedInnerboolEnvInvalid = errors.New(TESTCONFIGNESTED_NESTED_INNERBOOL_ENV)
 )
 
-func LoadTestConfigNested() (TestConfigNested, error) {
+// LoadTestConfigNested reads the config from the environment.
+func LoadTestConfigNested(opts ...LoadOption) (TestConfigNested, error) {
+	return LoadTestConfigNestedFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigNestedWithFile reads the config from the environment, falling
+// back to the JSON config file at path and then to the defaults.
+func LoadTestConfigNestedWithFile(path string, opts ...LoadOption) (TestConfigNested, error) {
+	return LoadTestConfigNested(append(opts, WithConfigFile(path))...)
+}
+
+// LoadTestConfigNestedWithFlags reads the config from the flags set on fs,
+// falling back to the environment and then to the defaults. The flags must
+// have been registered with RegisterTestConfigNestedFlags and fs must be parsed.
+func LoadTestConfigNestedWithFlags(fs *flag.FlagSet, opts ...LoadOption) (TestConfigNested, error) {
+	return LoadTestConfigNested(append(opts, WithFlags(fs))...)
+}
+
+// RegisterTestConfigNestedFlags defines a flag on fs for every field of
+// TestConfigNested, named after the lowercased field path, e.g. -server.port.
+// Values are parsed when the config is loaded, like env vars.
+func RegisterTestConfigNestedFlags(fs *flag.FlagSet) {
+	fs.Var(&flagValue{envVar: TESTCONFIGNESTED_APPNAME_ENV}, "appname", "env TESTCONFIGNESTED_APPNAME")
+	fs.Var(&flagValue{envVar: TESTCONFIGNESTED_NESTED_INNERSTR_ENV}, "nested.innerstr", "env TESTCONFIGNESTED_NESTED_INNERSTR")
+	fs.Var(&flagValue{envVar: TESTCONFIGNESTED_NESTED_INNERBOOL_ENV, isBool: true}, "nested.innerbool", "env TESTCONFIGNESTED_NESTED_INNERBOOL")
+}
+
+// LoadTestConfigNestedFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigNestedFromMap(m map[string]string, opts ...LoadOption) (TestConfigNested, error) {
+	return LoadTestConfigNestedFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigNestedFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigNestedFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigNested, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	sources := []func(string) (string, bool){lookup}
+	if options.configFile != "" {
+		values, err := readConfigFile(options.configFile, map[string]string{
+			"AppName":          TESTCONFIGNESTED_APPNAME_ENV,
+			"Nested.InnerStr":  TESTCONFIGNESTED_NESTED_INNERSTR_ENV,
+			"Nested.InnerBool": TESTCONFIGNESTED_NESTED_INNERBOOL_ENV,
+		})
+		if err != nil {
+			return TestConfigNested{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+
+	if options.flagSet != nil {
+		flagValues := map[string]string{}
+		options.flagSet.Visit(func(f *flag.Flag) {
+			if v, ok := f.Value.(*flagValue); ok {
+				flagValues[v.envVar] = v.value
+			}
+		})
+		sources = append([]func(string) (string, bool){func(name string) (string, bool) {
+			value, ok := flagValues[name]
+			return value, ok
+		}}, sources...)
+	}
+	lookup = layerLookups(nil, sources...)
+
 	var config TestConfigNested
 	var missingVars []error
 	var formatVars []error
-	val_AppName, ok := os.LookupEnv(TESTCONFIGNESTED_APPNAME_ENV)
+	val_AppName, ok := lookup(TESTCONFIGNESTED_APPNAME_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfignestedAppnameEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "AppName", EnvVar: TESTCONFIGNESTED_APPNAME_ENV, Kind: "missing", sentinel: ErrTestconfignestedAppnameEnvMissing})
 	} else {
 		config.AppName = val_AppName
 	}
-	val_Nested_InnerStr, ok := os.LookupEnv(TESTCONFIGNESTED_NESTED_INNERSTR_ENV)
+	val_Nested_InnerStr, ok := lookup(TESTCONFIGNESTED_NESTED_INNERSTR_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfignestedNestedInnerstrEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Nested.InnerStr", EnvVar: TESTCONFIGNESTED_NESTED_INNERSTR_ENV, Kind: "missing", sentinel: ErrTestconfignestedNestedInnerstrEnvMissing})
 	} else {
 		config.Nested.InnerStr = val_Nested_InnerStr
 	}
-	val_Nested_InnerBool, ok := os.LookupEnv(TESTCONFIGNESTED_NESTED_INNERBOOL_ENV)
+	val_Nested_InnerBool, ok := lookup(TESTCONFIGNESTED_NESTED_INNERBOOL_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfignestedNestedInnerboolEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Nested.InnerBool", EnvVar: TESTCONFIGNESTED_NESTED_INNERBOOL_ENV, Kind: "missing", sentinel: ErrTestconfignestedNestedInnerboolEnvMissing})
 	} else {
 		parsed, err := strconv.ParseBool(val_Nested_InnerBool)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfignestedNestedInnerboolEnvInvalid)
+			formatVars = append(formatVars, newFieldError("Nested.InnerBool", TESTCONFIGNESTED_NESTED_INNERBOOL_ENV, ErrTestconfignestedNestedInnerboolEnvInvalid, val_Nested_InnerBool, false, err))
 		} else {
 			config.Nested.InnerBool = parsed
 		}
@@ -66,6 +141,123 @@ func LoadTestConfigNested() (TestConfigNested, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning  func(Warning)
+	configFile string
+	flagSet    *flag.FlagSet
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+// WithConfigFile layers the values of a JSON config file under the real
+// environment and any dotenv files. The document mirrors the nesting of
+// the config struct, keyed by field name, e.g. {"Server": {"Port": 8080}}.
+func WithConfigFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.configFile = path
+	}
+}
+
+// WithFlags gives the flags set on fs precedence over every other source.
+// fs must be parsed and its flags registered with the Register...Flags
+// function of the config.
+func WithFlags(fs *flag.FlagSet) LoadOption {
+	return func(o *loadOptions) {
+		o.flagSet = fs
+	}
+}
+
+// flagValue holds the raw value of a flag, which is parsed together with
+// the other sources of envVar.
+type flagValue struct {
+	envVar string
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -75,14 +267,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -94,12 +279,119 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// layerLookups combines sources, earlier ones taking precedence. The names of
+// each group in aliases belong to one field and are all read from the first
+// source that sets any of them, so that a source wins outright instead of
+// mixing its value with the aliases set in the sources below it.
+func layerLookups(aliases [][]string, sources ...func(string) (string, bool)) func(string) (string, bool) {
+	groups := map[string][]string{}
+	for _, names := range aliases {
+		for _, name := range names {
+			groups[name] = names
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			if value, ok := source(name); ok {
+				return value, true
+			}
+			for _, alias := range groups[name] {
+				if alias == name {
+					continue
+				}
+				if _, ok := source(alias); ok {
+					return "", false
+				}
+			}
+		}
+		return "", false
+	}
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// readConfigFile reads a JSON config file and returns its values keyed by
+// env var. paths maps the dotted path of every field to its env var; other
+// keys are rejected so that typos do not go unnoticed. Values are kept as
+// text and parsed like the environment, and null leaves a field unset.
+func readConfigFile(path string, paths map[string]string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	values := map[string]string{}
+	if err := flattenConfigFile(path, "", doc, paths, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenConfigFile(file string, prefix string, node map[string]any, paths map[string]string, values map[string]string) error {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fieldPath := key
+		if prefix != "" {
+			fieldPath = prefix + "." + key
+		}
+		if section, ok := node[key].(map[string]any); ok {
+			if err := flattenConfigFile(file, fieldPath, section, paths, values); err != nil {
+				return err
+			}
+			continue
+		}
+		name, ok := paths[fieldPath]
+		if !ok {
+			return errors.New(file + ": unknown field " + fieldPath)
+		}
+		switch v := node[key].(type) {
+		case nil:
+		case string:
+			values[name] = v
+		case json.Number:
+			values[name] = v.String()
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		default:
+			return errors.New(file + ": field " + fieldPath + " must be a string, number or boolean")
+		}
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return nil
 }
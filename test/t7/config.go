@@ -22,4 +22,5 @@ type TestConfigDefaults struct {
 	F32      float32       `default:"1.5"`
 	F64      float64       `default:"2.5"`
 	D        time.Duration `default:"1500ms"`
+	Quoted   string        `default:"it's \"quoted\"\tC:\\tmp\nnext"`
 }
@@ -5,10 +5,14 @@
 package t7
 
 import (
+	"encoding/json"
 	"errors"
+	"flag"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 )
 
@@ -29,6 +33,7 @@ const (
 	TESTCONFIGDEFAULTS_F32_ENV      = "TESTCONFIGDEFAULTS_F32"
 	TESTCONFIGDEFAULTS_F64_ENV      = "TESTCONFIGDEFAULTS_F64"
 	TESTCONFIGDEFAULTS_D_ENV        = "TESTCONFIGDEFAULTS_D"
+	TESTCONFIGDEFAULTS_QUOTED_ENV   = "TESTCONFIGDEFAULTS_QUOTED"
 )
 
 var (
@@ -49,17 +54,147 @@ var (
 	ErrTestconfigdefaultsDEnvInvalid        = errors.New(TESTCONFIGDEFAULTS_D_ENV)
 )
 
-func LoadTestConfigDefaults() (TestConfigDefaults, error) {
+func init() {
+	fieldInfos[TESTCONFIGDEFAULTS_REQUIRED_ENV] = fieldInfo{path: "Required", typ: "string", doc: "no default — must still be set"}
+	fieldInfos[TESTCONFIGDEFAULTS_STR_ENV] = fieldInfo{path: "Str", typ: "string", def: "hello", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_B_ENV] = fieldInfo{path: "B", typ: "bool (true or false)", def: "true", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_I_ENV] = fieldInfo{path: "I", typ: "int (e.g. 42)", def: "-5", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_I8_ENV] = fieldInfo{path: "I8", typ: "int8 (e.g. 42)", def: "-1", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_I16_ENV] = fieldInfo{path: "I16", typ: "int16 (e.g. 42)", def: "16", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_I32_ENV] = fieldInfo{path: "I32", typ: "int32 (e.g. 42)", def: "32", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_I64_ENV] = fieldInfo{path: "I64", typ: "int64 (e.g. 42)", def: "64", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_U_ENV] = fieldInfo{path: "U", typ: "uint (e.g. 42)", def: "5", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_U8_ENV] = fieldInfo{path: "U8", typ: "uint8 (e.g. 42)", def: "1", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_U16_ENV] = fieldInfo{path: "U16", typ: "uint16 (e.g. 42)", def: "16", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_U32_ENV] = fieldInfo{path: "U32", typ: "uint32 (e.g. 42)", def: "32", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_U64_ENV] = fieldInfo{path: "U64", typ: "uint64 (e.g. 42)", def: "64", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_F32_ENV] = fieldInfo{path: "F32", typ: "float32 (e.g. 0.5)", def: "1.5", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_F64_ENV] = fieldInfo{path: "F64", typ: "float64 (e.g. 0.5)", def: "2.5", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_D_ENV] = fieldInfo{path: "D", typ: "duration (e.g. 30s or 1h30m)", def: "1500ms", hasDefault: true}
+	fieldInfos[TESTCONFIGDEFAULTS_QUOTED_ENV] = fieldInfo{path: "Quoted", typ: "string", def: "it's \"quoted\"\tC:\\tmp\nnext", hasDefault: true}
+}
+
+// LoadTestConfigDefaults reads the config from the environment.
+func LoadTestConfigDefaults(opts ...LoadOption) (TestConfigDefaults, error) {
+	return LoadTestConfigDefaultsFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigDefaultsWithFile reads the config from the environment, falling
+// back to the JSON config file at path and then to the defaults.
+func LoadTestConfigDefaultsWithFile(path string, opts ...LoadOption) (TestConfigDefaults, error) {
+	return LoadTestConfigDefaults(append(opts, WithConfigFile(path))...)
+}
+
+// LoadTestConfigDefaultsWithFlags reads the config from the flags set on fs,
+// falling back to the environment and then to the defaults. The flags must
+// have been registered with RegisterTestConfigDefaultsFlags and fs must be parsed.
+func LoadTestConfigDefaultsWithFlags(fs *flag.FlagSet, opts ...LoadOption) (TestConfigDefaults, error) {
+	return LoadTestConfigDefaults(append(opts, WithFlags(fs))...)
+}
+
+// RegisterTestConfigDefaultsFlags defines a flag on fs for every field of
+// TestConfigDefaults, named after the lowercased field path, e.g. -server.port.
+// Values are parsed when the config is loaded, like env vars.
+func RegisterTestConfigDefaultsFlags(fs *flag.FlagSet) {
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_REQUIRED_ENV}, "required", "no default — must still be set (env TESTCONFIGDEFAULTS_REQUIRED)")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_STR_ENV, value: "hello"}, "str", "env TESTCONFIGDEFAULTS_STR")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_B_ENV, value: "true", isBool: true}, "b", "env TESTCONFIGDEFAULTS_B")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_I_ENV, value: "-5"}, "i", "env TESTCONFIGDEFAULTS_I")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_I8_ENV, value: "-1"}, "i8", "env TESTCONFIGDEFAULTS_I8")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_I16_ENV, value: "16"}, "i16", "env TESTCONFIGDEFAULTS_I16")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_I32_ENV, value: "32"}, "i32", "env TESTCONFIGDEFAULTS_I32")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_I64_ENV, value: "64"}, "i64", "env TESTCONFIGDEFAULTS_I64")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_U_ENV, value: "5"}, "u", "env TESTCONFIGDEFAULTS_U")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_U8_ENV, value: "1"}, "u8", "env TESTCONFIGDEFAULTS_U8")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_U16_ENV, value: "16"}, "u16", "env TESTCONFIGDEFAULTS_U16")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_U32_ENV, value: "32"}, "u32", "env TESTCONFIGDEFAULTS_U32")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_U64_ENV, value: "64"}, "u64", "env TESTCONFIGDEFAULTS_U64")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_F32_ENV, value: "1.5"}, "f32", "env TESTCONFIGDEFAULTS_F32")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_F64_ENV, value: "2.5"}, "f64", "env TESTCONFIGDEFAULTS_F64")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_D_ENV, value: "1500ms"}, "d", "env TESTCONFIGDEFAULTS_D")
+	fs.Var(&flagValue{envVar: TESTCONFIGDEFAULTS_QUOTED_ENV, value: "it's \"quoted\"\tC:\\tmp\nnext"}, "quoted", "env TESTCONFIGDEFAULTS_QUOTED")
+}
+
+// LoadTestConfigDefaultsFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigDefaultsFromMap(m map[string]string, opts ...LoadOption) (TestConfigDefaults, error) {
+	return LoadTestConfigDefaultsFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigDefaultsFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigDefaultsFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigDefaults, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	sources := []func(string) (string, bool){lookup}
+	if len(options.dotenvFiles) > 0 {
+		values, err := readDotenvFiles(options.dotenvFiles, false)
+		if err != nil {
+			return TestConfigDefaults{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+	if options.configFile != "" {
+		values, err := readConfigFile(options.configFile, map[string]string{
+			"Required": TESTCONFIGDEFAULTS_REQUIRED_ENV,
+			"Str":      TESTCONFIGDEFAULTS_STR_ENV,
+			"B":        TESTCONFIGDEFAULTS_B_ENV,
+			"I":        TESTCONFIGDEFAULTS_I_ENV,
+			"I8":       TESTCONFIGDEFAULTS_I8_ENV,
+			"I16":      TESTCONFIGDEFAULTS_I16_ENV,
+			"I32":      TESTCONFIGDEFAULTS_I32_ENV,
+			"I64":      TESTCONFIGDEFAULTS_I64_ENV,
+			"U":        TESTCONFIGDEFAULTS_U_ENV,
+			"U8":       TESTCONFIGDEFAULTS_U8_ENV,
+			"U16":      TESTCONFIGDEFAULTS_U16_ENV,
+			"U32":      TESTCONFIGDEFAULTS_U32_ENV,
+			"U64":      TESTCONFIGDEFAULTS_U64_ENV,
+			"F32":      TESTCONFIGDEFAULTS_F32_ENV,
+			"F64":      TESTCONFIGDEFAULTS_F64_ENV,
+			"D":        TESTCONFIGDEFAULTS_D_ENV,
+			"Quoted":   TESTCONFIGDEFAULTS_QUOTED_ENV,
+		})
+		if err != nil {
+			return TestConfigDefaults{}, err
+		}
+		sources = append(sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+
+	if options.flagSet != nil {
+		flagValues := map[string]string{}
+		options.flagSet.Visit(func(f *flag.Flag) {
+			if v, ok := f.Value.(*flagValue); ok {
+				flagValues[v.envVar] = v.value
+			}
+		})
+		sources = append([]func(string) (string, bool){func(name string) (string, bool) {
+			value, ok := flagValues[name]
+			return value, ok
+		}}, sources...)
+	}
+	lookup = layerLookups(nil, sources...)
+
 	var config TestConfigDefaults
 	var missingVars []error
 	var formatVars []error
-	val_Required, ok := os.LookupEnv(TESTCONFIGDEFAULTS_REQUIRED_ENV)
+	val_Required, ok := lookup(TESTCONFIGDEFAULTS_REQUIRED_ENV)
 	if !ok {
-		missingVars = append(missingVars, ErrTestconfigdefaultsRequiredEnvMissing)
+		missingVars = append(missingVars, FieldError{Path: "Required", EnvVar: TESTCONFIGDEFAULTS_REQUIRED_ENV, Kind: "missing", sentinel: ErrTestconfigdefaultsRequiredEnvMissing})
 	} else {
 		config.Required = val_Required
 	}
-	val_Str, ok := os.LookupEnv(TESTCONFIGDEFAULTS_STR_ENV)
+	val_Str, ok := lookup(TESTCONFIGDEFAULTS_STR_ENV)
 	if !ok {
 		val_Str = "hello"
 		ok = true
@@ -67,7 +202,7 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		config.Str = val_Str
 	}
-	val_B, ok := os.LookupEnv(TESTCONFIGDEFAULTS_B_ENV)
+	val_B, ok := lookup(TESTCONFIGDEFAULTS_B_ENV)
 	if !ok {
 		val_B = "true"
 		ok = true
@@ -75,12 +210,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseBool(val_B)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsBEnvInvalid)
+			formatVars = append(formatVars, newFieldError("B", TESTCONFIGDEFAULTS_B_ENV, ErrTestconfigdefaultsBEnvInvalid, val_B, false, err))
 		} else {
 			config.B = parsed
 		}
 	}
-	val_I, ok := os.LookupEnv(TESTCONFIGDEFAULTS_I_ENV)
+	val_I, ok := lookup(TESTCONFIGDEFAULTS_I_ENV)
 	if !ok {
 		val_I = "-5"
 		ok = true
@@ -88,12 +223,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.Atoi(val_I)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsIEnvInvalid)
+			formatVars = append(formatVars, newFieldError("I", TESTCONFIGDEFAULTS_I_ENV, ErrTestconfigdefaultsIEnvInvalid, val_I, false, err))
 		} else {
 			config.I = parsed
 		}
 	}
-	val_I8, ok := os.LookupEnv(TESTCONFIGDEFAULTS_I8_ENV)
+	val_I8, ok := lookup(TESTCONFIGDEFAULTS_I8_ENV)
 	if !ok {
 		val_I8 = "-1"
 		ok = true
@@ -101,12 +236,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseInt(val_I8, 10, 8)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsI8EnvInvalid)
+			formatVars = append(formatVars, newFieldError("I8", TESTCONFIGDEFAULTS_I8_ENV, ErrTestconfigdefaultsI8EnvInvalid, val_I8, false, err))
 		} else {
 			config.I8 = int8(parsed)
 		}
 	}
-	val_I16, ok := os.LookupEnv(TESTCONFIGDEFAULTS_I16_ENV)
+	val_I16, ok := lookup(TESTCONFIGDEFAULTS_I16_ENV)
 	if !ok {
 		val_I16 = "16"
 		ok = true
@@ -114,12 +249,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseInt(val_I16, 10, 16)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsI16EnvInvalid)
+			formatVars = append(formatVars, newFieldError("I16", TESTCONFIGDEFAULTS_I16_ENV, ErrTestconfigdefaultsI16EnvInvalid, val_I16, false, err))
 		} else {
 			config.I16 = int16(parsed)
 		}
 	}
-	val_I32, ok := os.LookupEnv(TESTCONFIGDEFAULTS_I32_ENV)
+	val_I32, ok := lookup(TESTCONFIGDEFAULTS_I32_ENV)
 	if !ok {
 		val_I32 = "32"
 		ok = true
@@ -127,12 +262,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseInt(val_I32, 10, 32)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsI32EnvInvalid)
+			formatVars = append(formatVars, newFieldError("I32", TESTCONFIGDEFAULTS_I32_ENV, ErrTestconfigdefaultsI32EnvInvalid, val_I32, false, err))
 		} else {
 			config.I32 = int32(parsed)
 		}
 	}
-	val_I64, ok := os.LookupEnv(TESTCONFIGDEFAULTS_I64_ENV)
+	val_I64, ok := lookup(TESTCONFIGDEFAULTS_I64_ENV)
 	if !ok {
 		val_I64 = "64"
 		ok = true
@@ -140,12 +275,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseInt(val_I64, 10, 64)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsI64EnvInvalid)
+			formatVars = append(formatVars, newFieldError("I64", TESTCONFIGDEFAULTS_I64_ENV, ErrTestconfigdefaultsI64EnvInvalid, val_I64, false, err))
 		} else {
 			config.I64 = int64(parsed)
 		}
 	}
-	val_U, ok := os.LookupEnv(TESTCONFIGDEFAULTS_U_ENV)
+	val_U, ok := lookup(TESTCONFIGDEFAULTS_U_ENV)
 	if !ok {
 		val_U = "5"
 		ok = true
@@ -153,12 +288,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseUint(val_U, 10, 0)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsUEnvInvalid)
+			formatVars = append(formatVars, newFieldError("U", TESTCONFIGDEFAULTS_U_ENV, ErrTestconfigdefaultsUEnvInvalid, val_U, false, err))
 		} else {
 			config.U = uint(parsed)
 		}
 	}
-	val_U8, ok := os.LookupEnv(TESTCONFIGDEFAULTS_U8_ENV)
+	val_U8, ok := lookup(TESTCONFIGDEFAULTS_U8_ENV)
 	if !ok {
 		val_U8 = "1"
 		ok = true
@@ -166,12 +301,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseUint(val_U8, 10, 8)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsU8EnvInvalid)
+			formatVars = append(formatVars, newFieldError("U8", TESTCONFIGDEFAULTS_U8_ENV, ErrTestconfigdefaultsU8EnvInvalid, val_U8, false, err))
 		} else {
 			config.U8 = uint8(parsed)
 		}
 	}
-	val_U16, ok := os.LookupEnv(TESTCONFIGDEFAULTS_U16_ENV)
+	val_U16, ok := lookup(TESTCONFIGDEFAULTS_U16_ENV)
 	if !ok {
 		val_U16 = "16"
 		ok = true
@@ -179,12 +314,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseUint(val_U16, 10, 16)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsU16EnvInvalid)
+			formatVars = append(formatVars, newFieldError("U16", TESTCONFIGDEFAULTS_U16_ENV, ErrTestconfigdefaultsU16EnvInvalid, val_U16, false, err))
 		} else {
 			config.U16 = uint16(parsed)
 		}
 	}
-	val_U32, ok := os.LookupEnv(TESTCONFIGDEFAULTS_U32_ENV)
+	val_U32, ok := lookup(TESTCONFIGDEFAULTS_U32_ENV)
 	if !ok {
 		val_U32 = "32"
 		ok = true
@@ -192,12 +327,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseUint(val_U32, 10, 32)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsU32EnvInvalid)
+			formatVars = append(formatVars, newFieldError("U32", TESTCONFIGDEFAULTS_U32_ENV, ErrTestconfigdefaultsU32EnvInvalid, val_U32, false, err))
 		} else {
 			config.U32 = uint32(parsed)
 		}
 	}
-	val_U64, ok := os.LookupEnv(TESTCONFIGDEFAULTS_U64_ENV)
+	val_U64, ok := lookup(TESTCONFIGDEFAULTS_U64_ENV)
 	if !ok {
 		val_U64 = "64"
 		ok = true
@@ -205,12 +340,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseUint(val_U64, 10, 64)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsU64EnvInvalid)
+			formatVars = append(formatVars, newFieldError("U64", TESTCONFIGDEFAULTS_U64_ENV, ErrTestconfigdefaultsU64EnvInvalid, val_U64, false, err))
 		} else {
 			config.U64 = uint64(parsed)
 		}
 	}
-	val_F32, ok := os.LookupEnv(TESTCONFIGDEFAULTS_F32_ENV)
+	val_F32, ok := lookup(TESTCONFIGDEFAULTS_F32_ENV)
 	if !ok {
 		val_F32 = "1.5"
 		ok = true
@@ -218,12 +353,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseFloat(val_F32, 32)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsF32EnvInvalid)
+			formatVars = append(formatVars, newFieldError("F32", TESTCONFIGDEFAULTS_F32_ENV, ErrTestconfigdefaultsF32EnvInvalid, val_F32, false, err))
 		} else {
 			config.F32 = float32(parsed)
 		}
 	}
-	val_F64, ok := os.LookupEnv(TESTCONFIGDEFAULTS_F64_ENV)
+	val_F64, ok := lookup(TESTCONFIGDEFAULTS_F64_ENV)
 	if !ok {
 		val_F64 = "2.5"
 		ok = true
@@ -231,12 +366,12 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := strconv.ParseFloat(val_F64, 64)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsF64EnvInvalid)
+			formatVars = append(formatVars, newFieldError("F64", TESTCONFIGDEFAULTS_F64_ENV, ErrTestconfigdefaultsF64EnvInvalid, val_F64, false, err))
 		} else {
 			config.F64 = parsed
 		}
 	}
-	val_D, ok := os.LookupEnv(TESTCONFIGDEFAULTS_D_ENV)
+	val_D, ok := lookup(TESTCONFIGDEFAULTS_D_ENV)
 	if !ok {
 		val_D = "1500ms"
 		ok = true
@@ -244,11 +379,19 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	if ok {
 		parsed, err := time.ParseDuration(val_D)
 		if err != nil {
-			formatVars = append(formatVars, ErrTestconfigdefaultsDEnvInvalid)
+			formatVars = append(formatVars, newFieldError("D", TESTCONFIGDEFAULTS_D_ENV, ErrTestconfigdefaultsDEnvInvalid, val_D, false, err))
 		} else {
 			config.D = parsed
 		}
 	}
+	val_Quoted, ok := lookup(TESTCONFIGDEFAULTS_QUOTED_ENV)
+	if !ok {
+		val_Quoted = "it's \"quoted\"\tC:\\tmp\nnext"
+		ok = true
+	}
+	if ok {
+		config.Quoted = val_Quoted
+	}
 
 	if len(missingVars) > 0 || len(formatVars) > 0 {
 		var verr error
@@ -264,6 +407,202 @@ func LoadTestConfigDefaults() (TestConfigDefaults, error) {
 	return config, nil
 }
 
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning   func(Warning)
+	dotenvFiles []string
+	configFile  string
+	flagSet     *flag.FlagSet
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+// WithDotenv layers the values of dotenv files under the real environment.
+// Later files take precedence over earlier ones, and files that do not
+// exist are skipped, e.g. WithDotenv(".env", ".env.local").
+func WithDotenv(paths ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.dotenvFiles = append(o.dotenvFiles, paths...)
+	}
+}
+
+// WithConfigFile layers the values of a JSON config file under the real
+// environment and any dotenv files. The document mirrors the nesting of
+// the config struct, keyed by field name, e.g. {"Server": {"Port": 8080}}.
+func WithConfigFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.configFile = path
+	}
+}
+
+// WithFlags gives the flags set on fs precedence over every other source.
+// fs must be parsed and its flags registered with the Register...Flags
+// function of the config.
+func WithFlags(fs *flag.FlagSet) LoadOption {
+	return func(o *loadOptions) {
+		o.flagSet = fs
+	}
+}
+
+// flagValue holds the raw value of a flag, which is parsed together with
+// the other sources of envVar.
+type flagValue struct {
+	envVar string
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// fieldInfo describes a field of a config for FormatConfigError.
+type fieldInfo struct {
+	path       string
+	typ        string // type with an example value, e.g. duration (e.g. 30s)
+	def        string
+	hasDefault bool
+	doc        string
+}
+
+// fieldInfos holds the fields of every config of the package, keyed by env
+// var. Each config adds its fields when the package is initialized.
+var fieldInfos = map[string]fieldInfo{}
+
+// FormatConfigError formats an error returned by a Load function as a table
+// with one row per env var at fault, showing its field, type with an example
+// value, default, what went wrong and the doc comment of the field. Errors
+// that are not about a single env var, such as those of Validate methods,
+// are listed below the table.
+func FormatConfigError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var rows [][2]string // env var and problem
+	var others []string
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case FieldError:
+			if e.Kind == "missing" {
+				rows = append(rows, [2]string{e.EnvVar, "not set"})
+			} else {
+				rows = append(rows, [2]string{e.EnvVar, "invalid value " + strconv.Quote(e.Value) + ": " + e.reason})
+			}
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		default:
+			others = append(others, err.Error())
+		}
+	}
+	walk(err)
+
+	var table strings.Builder
+	if len(rows) > 0 {
+		w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		w.Write([]byte("ENV VAR\tFIELD\tTYPE\tDEFAULT\tPROBLEM\tDESCRIPTION\n"))
+		for _, row := range rows {
+			info, ok := fieldInfos[row[0]]
+			if !ok {
+				info = fieldInfo{path: "-", typ: "-"}
+			}
+			def := "-"
+			if info.hasDefault {
+				def = strconv.Quote(info.def)
+			}
+			w.Write([]byte(strings.Join([]string{row[0], info.path, info.typ, def, row[1], info.doc}, "\t") + "\n"))
+		}
+		w.Flush()
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+		if line = strings.TrimRight(line, " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(append(lines, others...), "\n")
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
 type MissingEnvVarsError struct {
 	vars []error
 }
@@ -273,14 +612,7 @@ func (m MissingEnvVarsError) Unwrap() []error {
 }
 
 func (m MissingEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
-	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
-	}
-	return "envs " + strings.Join(varsstr, ",") + " are not set"
+	return joinEnvVars("envs ", m.vars, " are not set")
 }
 
 type InvalidEnvVarsError struct {
@@ -292,12 +624,264 @@ func (m InvalidEnvVarsError) Unwrap() []error {
 }
 
 func (m InvalidEnvVarsError) Error() string {
-	if len(m.vars) == 0 {
-		return ""
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// layerLookups combines sources, earlier ones taking precedence. The names of
+// each group in aliases belong to one field and are all read from the first
+// source that sets any of them, so that a source wins outright instead of
+// mixing its value with the aliases set in the sources below it.
+func layerLookups(aliases [][]string, sources ...func(string) (string, bool)) func(string) (string, bool) {
+	groups := map[string][]string{}
+	for _, names := range aliases {
+		for _, name := range names {
+			groups[name] = names
+		}
 	}
-	varsstr := make([]string, 0, len(m.vars))
-	for _, v := range m.vars {
-		varsstr = append(varsstr, v.Error())
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			if value, ok := source(name); ok {
+				return value, true
+			}
+			for _, alias := range groups[name] {
+				if alias == name {
+					continue
+				}
+				if _, ok := source(alias); ok {
+					return "", false
+				}
+			}
+		}
+		return "", false
+	}
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// readConfigFile reads a JSON config file and returns its values keyed by
+// env var. paths maps the dotted path of every field to its env var; other
+// keys are rejected so that typos do not go unnoticed. Values are kept as
+// text and parsed like the environment, and null leaves a field unset.
+func readConfigFile(path string, paths map[string]string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	values := map[string]string{}
+	if err := flattenConfigFile(path, "", doc, paths, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenConfigFile(file string, prefix string, node map[string]any, paths map[string]string, values map[string]string) error {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fieldPath := key
+		if prefix != "" {
+			fieldPath = prefix + "." + key
+		}
+		if section, ok := node[key].(map[string]any); ok {
+			if err := flattenConfigFile(file, fieldPath, section, paths, values); err != nil {
+				return err
+			}
+			continue
+		}
+		name, ok := paths[fieldPath]
+		if !ok {
+			return errors.New(file + ": unknown field " + fieldPath)
+		}
+		switch v := node[key].(type) {
+		case nil:
+		case string:
+			values[name] = v
+		case json.Number:
+			values[name] = v.String()
+		case bool:
+			values[name] = strconv.FormatBool(v)
+		default:
+			return errors.New(file + ": field " + fieldPath + " must be a string, number or boolean")
+		}
+	}
+	return nil
+}
+
+// readDotenvFiles reads and merges dotenv files, later files taking
+// precedence. Files that do not exist are skipped. escapeDollar is passed
+// on to parseDotenv.
+func readDotenvFiles(paths []string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := parseDotenv(path, string(data), escapeDollar)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// parseDotenv parses the contents of a dotenv file. It supports comments,
+// an optional export prefix, single quoted values (taken literally), double
+// quoted values (with \n, \r, \t, \", \\ and \$ escapes, possibly spanning
+// several lines) and unquoted values, which end at an inline " #" comment.
+// With escapeDollar, the literal dollars of \$ escapes and single quoted
+// values are written as $$, so that interpolation keeps them as they are.
+func parseDotenv(path string, data string, escapeDollar bool) (map[string]string, error) {
+	values := map[string]string{}
+	line := 1
+	fail := func(msg string) error {
+		return errors.New(path + ":" + strconv.Itoa(line) + ": " + msg)
+	}
+	lineEnd := func(i int) int {
+		if end := strings.IndexByte(data[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(data)
+	}
+	i := 0
+	for i < len(data) {
+		switch data[i] {
+		case '\n':
+			line++
+			i++
+			continue
+		case ' ', '\t', '\r':
+			i++
+			continue
+		case '#':
+			i = lineEnd(i)
+			continue
+		}
+
+		end := lineEnd(i)
+		if rest, ok := strings.CutPrefix(data[i:end], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			i += len("export")
+		}
+		eq := strings.IndexByte(data[i:end], '=')
+		if eq < 0 {
+			return nil, fail("expected KEY=value")
+		}
+		key := strings.TrimSpace(data[i : i+eq])
+		if key == "" || strings.ContainsAny(key, " \t'\"#") {
+			return nil, fail("invalid key " + strconv.Quote(key))
+		}
+		i += eq + 1
+		for i < end && (data[i] == ' ' || data[i] == '\t') {
+			i++
+		}
+
+		if i < end && (data[i] == '\'' || data[i] == '"') {
+			quote := data[i]
+			i++
+			startLine := line
+			var sb strings.Builder
+			closed := false
+			for i < len(data) {
+				c := data[i]
+				if c == quote {
+					closed = true
+					i++
+					break
+				}
+				if quote == '"' && c == '\\' && i+1 < len(data) {
+					switch next := data[i+1]; next {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					case '$':
+						if escapeDollar {
+							sb.WriteByte('$')
+						}
+						sb.WriteByte('$')
+					case '"', '\\':
+						sb.WriteByte(next)
+					default:
+						sb.WriteByte(c)
+						sb.WriteByte(next)
+						if next == '\n' {
+							line++
+						}
+					}
+					i += 2
+					continue
+				}
+				if c == '\n' {
+					line++
+				}
+				if quote == '\'' && c == '$' && escapeDollar {
+					sb.WriteByte('$')
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				line = startLine
+				return nil, fail("unterminated quoted value for " + key)
+			}
+			end = lineEnd(i)
+			if rest := strings.TrimSpace(data[i:end]); rest != "" && rest[0] != '#' {
+				return nil, fail("unexpected characters after quoted value for " + key)
+			}
+			values[key] = sb.String()
+		} else {
+			raw := data[i:end]
+			if comment := strings.Index(raw, " #"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			if comment := strings.Index(raw, "\t#"); comment >= 0 {
+				raw = raw[:comment]
+			}
+			values[key] = strings.TrimSpace(raw)
+		}
+		i = end
 	}
-	return "envs " + strings.Join(varsstr, ",") + " have an invalid value"
+	return values, nil
 }
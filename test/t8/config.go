@@ -0,0 +1,13 @@
+//go:build testcases
+// +build testcases
+
+package t8
+
+import "time"
+
+type TestConfigDurations struct {
+	Plain     time.Duration
+	Retention time.Duration `durationstyle:"extended" max:"90d"`
+	Rotation  time.Duration `durationstyle:"iso8601" min:"PT0.1S"`
+	Grace     time.Duration `durationstyle:"extended" default:"1w"`
+}
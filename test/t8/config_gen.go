@@ -0,0 +1,408 @@
+// Code generated by configgen.go; EDIT AT YOUR OWN RISK.
+//go:build testcases
+// +build testcases
+
+package t8
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TESTCONFIGDURATIONS_PLAIN_ENV     = "TESTCONFIGDURATIONS_PLAIN"
+	TESTCONFIGDURATIONS_RETENTION_ENV = "TESTCONFIGDURATIONS_RETENTION"
+	TESTCONFIGDURATIONS_ROTATION_ENV  = "TESTCONFIGDURATIONS_ROTATION"
+	TESTCONFIGDURATIONS_GRACE_ENV     = "TESTCONFIGDURATIONS_GRACE"
+)
+
+var (
+	ErrTestconfigdurationsPlainEnvMissing     = errors.New(TESTCONFIGDURATIONS_PLAIN_ENV)
+	ErrTestconfigdurationsPlainEnvInvalid     = errors.New(TESTCONFIGDURATIONS_PLAIN_ENV)
+	ErrTestconfigdurationsRetentionEnvMissing = errors.New(TESTCONFIGDURATIONS_RETENTION_ENV)
+	ErrTestconfigdurationsRetentionEnvInvalid = errors.New(TESTCONFIGDURATIONS_RETENTION_ENV)
+	ErrTestconfigdurationsRotationEnvMissing  = errors.New(TESTCONFIGDURATIONS_ROTATION_ENV)
+	ErrTestconfigdurationsRotationEnvInvalid  = errors.New(TESTCONFIGDURATIONS_ROTATION_ENV)
+	ErrTestconfigdurationsGraceEnvInvalid     = errors.New(TESTCONFIGDURATIONS_GRACE_ENV)
+)
+
+// LoadTestConfigDurations reads the config from the environment.
+func LoadTestConfigDurations(opts ...LoadOption) (TestConfigDurations, error) {
+	return LoadTestConfigDurationsFrom(os.LookupEnv, opts...)
+}
+
+// LoadTestConfigDurationsFromMap reads the config from m instead of the
+// environment.
+func LoadTestConfigDurationsFromMap(m map[string]string, opts ...LoadOption) (TestConfigDurations, error) {
+	return LoadTestConfigDurationsFrom(func(name string) (string, bool) {
+		value, ok := m[name]
+		return value, ok
+	}, opts...)
+}
+
+// LoadTestConfigDurationsFrom reads the config through lookup, which has the
+// same contract as os.LookupEnv.
+func LoadTestConfigDurationsFrom(lookup func(string) (string, bool), opts ...LoadOption) (TestConfigDurations, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var config TestConfigDurations
+	var missingVars []error
+	var formatVars []error
+	var violations []error
+	val_Plain, ok := lookup(TESTCONFIGDURATIONS_PLAIN_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Plain", EnvVar: TESTCONFIGDURATIONS_PLAIN_ENV, Kind: "missing", sentinel: ErrTestconfigdurationsPlainEnvMissing})
+	} else {
+		parsed, err := time.ParseDuration(val_Plain)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Plain", TESTCONFIGDURATIONS_PLAIN_ENV, ErrTestconfigdurationsPlainEnvInvalid, val_Plain, false, err))
+		} else {
+			config.Plain = parsed
+		}
+	}
+	val_Retention, ok := lookup(TESTCONFIGDURATIONS_RETENTION_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Retention", EnvVar: TESTCONFIGDURATIONS_RETENTION_ENV, Kind: "missing", sentinel: ErrTestconfigdurationsRetentionEnvMissing})
+	} else {
+		parsed, err := parseExtendedDuration(val_Retention)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Retention", TESTCONFIGDURATIONS_RETENTION_ENV, ErrTestconfigdurationsRetentionEnvInvalid, val_Retention, false, err))
+		} else {
+			config.Retention = parsed
+			if config.Retention > 7776000000000000 {
+				violations = append(violations, violation{envVar: TESTCONFIGDURATIONS_RETENTION_ENV, constraint: "max 90d"})
+			}
+		}
+	}
+	val_Rotation, ok := lookup(TESTCONFIGDURATIONS_ROTATION_ENV)
+	if !ok {
+		missingVars = append(missingVars, FieldError{Path: "Rotation", EnvVar: TESTCONFIGDURATIONS_ROTATION_ENV, Kind: "missing", sentinel: ErrTestconfigdurationsRotationEnvMissing})
+	} else {
+		parsed, err := parseISO8601Duration(val_Rotation)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Rotation", TESTCONFIGDURATIONS_ROTATION_ENV, ErrTestconfigdurationsRotationEnvInvalid, val_Rotation, false, err))
+		} else {
+			config.Rotation = parsed
+			if config.Rotation < 100000000 {
+				violations = append(violations, violation{envVar: TESTCONFIGDURATIONS_ROTATION_ENV, constraint: "min PT0.1S"})
+			}
+		}
+	}
+	val_Grace, ok := lookup(TESTCONFIGDURATIONS_GRACE_ENV)
+	if !ok {
+		val_Grace = "1w"
+		ok = true
+	}
+	if ok {
+		parsed, err := parseExtendedDuration(val_Grace)
+		if err != nil {
+			formatVars = append(formatVars, newFieldError("Grace", TESTCONFIGDURATIONS_GRACE_ENV, ErrTestconfigdurationsGraceEnvInvalid, val_Grace, false, err))
+		} else {
+			config.Grace = parsed
+		}
+	}
+
+	if len(missingVars) > 0 || len(formatVars) > 0 || len(violations) > 0 {
+		var verr error
+		if len(missingVars) > 0 {
+			verr = errors.Join(verr, MissingEnvVarsError{vars: missingVars})
+		}
+		if len(formatVars) > 0 {
+			verr = errors.Join(verr, InvalidEnvVarsError{vars: formatVars})
+		}
+		if len(violations) > 0 {
+			verr = errors.Join(verr, ValidationError{vars: violations})
+		}
+		return TestConfigDurations{}, verr
+	}
+
+	return config, nil
+}
+
+// LoadOption configures how the config is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	onWarning func(Warning)
+}
+
+// WithWarningHandler registers a callback for non-fatal issues found while
+// loading the config, such as values read from deprecated aliases.
+func WithWarningHandler(fn func(Warning)) LoadOption {
+	return func(o *loadOptions) {
+		o.onWarning = fn
+	}
+}
+
+func (o loadOptions) warn(w Warning) {
+	if o.onWarning != nil {
+		o.onWarning(w)
+	}
+}
+
+// Warning describes a value that was read from a deprecated alias of its
+// env var.
+type Warning struct {
+	EnvVar string
+	Alias  string
+}
+
+func (w Warning) String() string {
+	return "env " + w.Alias + " is deprecated, use " + w.EnvVar + " instead"
+}
+
+// FieldError is a field that could not be loaded. errors.Is matches it
+// against the Err...EnvMissing or Err...EnvInvalid sentinel of its env var,
+// and against Err, the error returned when parsing the value.
+type FieldError struct {
+	Path   string // field path, e.g. Server.Port
+	EnvVar string
+	Kind   string // missing, syntax, range or invalid
+	Value  string // raw value, or [redacted] if it came from a secret reference
+	Err    error  // the parse error, nil for missing fields
+
+	sentinel error
+	reason   string
+}
+
+func (e FieldError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.sentinel}
+	}
+	return []error{e.sentinel, e.Err}
+}
+
+func (e FieldError) Error() string {
+	if e.reason == "" {
+		return e.EnvVar
+	}
+	return e.EnvVar + " (" + e.reason + ")"
+}
+
+// joinEnvVars formats the errors listed by an error type as prefix, the
+// comma separated errors and suffix, or "" if there are none.
+func joinEnvVars(prefix string, vars []error, suffix string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	varsstr := make([]string, 0, len(vars))
+	for _, v := range vars {
+		varsstr = append(varsstr, v.Error())
+	}
+	return prefix + strings.Join(varsstr, ",") + suffix
+}
+
+type MissingEnvVarsError struct {
+	vars []error
+}
+
+func (m MissingEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m MissingEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " are not set")
+}
+
+type InvalidEnvVarsError struct {
+	vars []error
+}
+
+func (m InvalidEnvVarsError) Unwrap() []error {
+	return m.vars
+}
+
+func (m InvalidEnvVarsError) Error() string {
+	return joinEnvVars("envs ", m.vars, " have an invalid value")
+}
+
+// ValidationError lists the env vars whose values violate the constraints
+// of their fields, each with the constraint that failed.
+type ValidationError struct {
+	vars []error
+}
+
+func (m ValidationError) Unwrap() []error {
+	return m.vars
+}
+
+func (m ValidationError) Error() string {
+	return joinEnvVars("envs ", m.vars, " violate their constraints")
+}
+
+type violation struct {
+	envVar     string
+	constraint string
+}
+
+func (v violation) Error() string {
+	return v.envVar + " (" + v.constraint + ")"
+}
+
+// newFieldError describes a value that failed to parse. Neither the reason
+// nor Err include the value of a secret, but a strconv error keeps its kind.
+func newFieldError(path, envVar string, sentinel error, value string, secret bool, err error) FieldError {
+	fe := FieldError{Path: path, EnvVar: envVar, Kind: "invalid", Value: value, Err: err, sentinel: sentinel, reason: err.Error()}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		fe.reason = numErr.Err.Error()
+		switch {
+		case errors.Is(numErr.Err, strconv.ErrSyntax):
+			fe.Kind = "syntax"
+		case errors.Is(numErr.Err, strconv.ErrRange):
+			fe.Kind = "range"
+		}
+	}
+	if secret {
+		fe.Value = "[redacted]"
+		if numErr != nil {
+			fe.Err = &strconv.NumError{Func: numErr.Func, Num: fe.Value, Err: numErr.Err}
+		} else {
+			fe.reason = "invalid value"
+			fe.Err = errors.New(fe.reason)
+		}
+	}
+	return fe
+}
+
+// parseExtendedDuration accepts everything time.ParseDuration does, plus
+// the units "d" (24h) and "w" (7d), e.g. "1w2d12h".
+func parseExtendedDuration(s string) (time.Duration, error) {
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, errors.New("invalid duration " + strconv.Quote(orig))
+	}
+	var d time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && (s[i] == '.' || ('0' <= s[i] && s[i] <= '9')) {
+			i++
+		}
+		j := i
+		for j < len(s) && s[j] != '.' && (s[j] < '0' || s[j] > '9') {
+			j++
+		}
+		num, unit := s[:i], s[i:j]
+		s = s[j:]
+		if num == "" || unit == "" {
+			return 0, errors.New("invalid duration " + strconv.Quote(orig))
+		}
+		var part time.Duration
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig))
+			}
+			u := 24 * time.Hour
+			if unit == "w" {
+				u = 7 * 24 * time.Hour
+			}
+			v := math.Round(f * float64(u))
+			if v >= math.MaxInt64 {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig) + ": out of range")
+			}
+			part = time.Duration(v)
+		default:
+			p, err := time.ParseDuration(num + unit)
+			if err != nil {
+				return 0, errors.New("invalid duration " + strconv.Quote(orig))
+			}
+			part = p
+		}
+		if d > math.MaxInt64-part {
+			return 0, errors.New("invalid duration " + strconv.Quote(orig) + ": out of range")
+		}
+		d += part
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseISO8601Duration parses ISO 8601 durations such as "P7D" or
+// "PT1H30M". Years and months have no fixed length and are rejected.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	invalid := errors.New("invalid ISO 8601 duration " + strconv.Quote(s))
+	rest := strings.ToUpper(s)
+	neg := false
+	if rest != "" && (rest[0] == '-' || rest[0] == '+') {
+		neg = rest[0] == '-'
+		rest = rest[1:]
+	}
+	if rest == "" || rest[0] != 'P' {
+		return 0, invalid
+	}
+	rest = rest[1:]
+	var d time.Duration
+	inTime, seen := false, false
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime || len(rest) == 1 {
+				return 0, invalid
+			}
+			inTime = true
+			rest = rest[1:]
+			continue
+		}
+		i := 0
+		for i < len(rest) && (rest[i] == '.' || rest[i] == ',' || ('0' <= rest[i] && rest[i] <= '9')) {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return 0, invalid
+		}
+		f, err := strconv.ParseFloat(strings.Replace(rest[:i], ",", ".", 1), 64)
+		if err != nil {
+			return 0, invalid
+		}
+		designator := rest[i]
+		rest = rest[i+1:]
+		var unit time.Duration
+		switch {
+		case !inTime && designator == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && designator == 'D':
+			unit = 24 * time.Hour
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, errors.New("invalid ISO 8601 duration " + strconv.Quote(s) + ": years and months have no fixed length")
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		default:
+			return 0, invalid
+		}
+		v := math.Round(f * float64(unit))
+		if v >= math.MaxInt64 || d > math.MaxInt64-time.Duration(v) {
+			return 0, errors.New("invalid ISO 8601 duration " + strconv.Quote(s) + ": out of range")
+		}
+		d += time.Duration(v)
+		seen = true
+	}
+	if !seen {
+		return 0, invalid
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,14 @@
+//go:build testcases
+// +build testcases
+
+package t9
+
+import "time"
+
+type TestConfigNormalize struct {
+	Level   string        `normalize:"trim,lower"`
+	Region  string        `normalize:"upper"`
+	Raw     string        // left untouched
+	Port    int           `normalize:"trim"`
+	Timeout time.Duration `normalize:"trim,lower" default:" 5S "`
+}
@@ -0,0 +1,14 @@
+package collisions
+
+type My struct {
+	Field string
+}
+
+type Config struct {
+	My_Field string
+	My       My
+	Port     int    `env:"PORT"`
+	Other    int    `env:"PORT"`
+	URL      string `env:"URL"`
+	Url      string `env:"LINK"`
+}
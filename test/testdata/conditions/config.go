@@ -0,0 +1,6 @@
+package conditions
+
+type Config struct {
+	Port     int    `default:"8080"`
+	Password string `requiredwith:"User"`
+}
@@ -0,0 +1,6 @@
+package profiles
+
+type Config struct {
+	LogLevel string `default:"info" default.dev:"debug"`
+	Profile  string
+}
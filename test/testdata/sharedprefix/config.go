@@ -0,0 +1,11 @@
+package sharedprefix
+
+type APIConfig struct {
+	LogLevel string `default:"info"`
+	Port     int    `default:"8080"`
+}
+
+type WorkerConfig struct {
+	LogLevel string `default:"info"`
+	Queue    string
+}
@@ -8,7 +8,7 @@ import (
 
 func main() {
 	var err error
-	err = genconfig.GenerateConfigLoader("TESTCONFIG1", "TestConfig1", "t1/config.go", "t1/config_gen.go", "", "testcases", false)
+	err = genconfig.GenerateConfigLoaderWithOptions("TESTCONFIG1", "TestConfig1", "t1/config.go", "t1/config_gen.go", "", "testcases", false, genconfig.Options{Dotenv: true, JSONFile: true, Flags: true})
 	if err != nil {
 		fmt.Println("TESTCONFIG1", err)
 	}
@@ -28,12 +28,88 @@ func main() {
 	if err != nil {
 		fmt.Println("TESTCONFIGFLOATS", err)
 	}
-	err = genconfig.GenerateConfigLoader("TESTCONFIGNESTED", "TestConfigNested", "t6/config.go", "t6/config_gen.go", "", "testcases", false)
+	err = genconfig.GenerateConfigLoaderWithOptions("TESTCONFIGNESTED", "TestConfigNested", "t6/config.go", "t6/config_gen.go", "", "testcases", false, genconfig.Options{JSONFile: true, Flags: true})
 	if err != nil {
 		fmt.Println("TESTCONFIGNESTED", err)
 	}
-	err = genconfig.GenerateConfigLoader("TESTCONFIGDEFAULTS", "TestConfigDefaults", "t7/config.go", "t7/config_gen.go", "", "testcases", false)
+	err = genconfig.GenerateConfigLoaderWithOptions("TESTCONFIGDEFAULTS", "TestConfigDefaults", "t7/config.go", "t7/config_gen.go", "", "testcases", false, genconfig.Options{Dotenv: true, JSONFile: true, Flags: true, ErrorReport: true})
 	if err != nil {
 		fmt.Println("TESTCONFIGDEFAULTS", err)
 	}
+	err = genconfig.GenerateConfigLoader("TESTCONFIGDURATIONS", "TestConfigDurations", "t8/config.go", "t8/config_gen.go", "", "testcases", false)
+	if err != nil {
+		fmt.Println("TESTCONFIGDURATIONS", err)
+	}
+	err = genconfig.GenerateConfigLoader("TESTCONFIGNORMALIZE", "TestConfigNormalize", "t9/config.go", "t9/config_gen.go", "", "testcases", false)
+	if err != nil {
+		fmt.Println("TESTCONFIGNORMALIZE", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("TESTCONFIGENVOVERRIDE", "TestConfigEnvOverride", "t10/config.go", "t10/config_gen.go", "", "testcases", false, genconfig.Options{Dotenv: true, Secrets: true})
+	if err != nil {
+		fmt.Println("TESTCONFIGENVOVERRIDE", err)
+	}
+	err = genconfig.GenerateConfigLoader("TESTCONFIGENVPREFIX", "TestConfigEnvPrefix", "t11/config.go", "t11/config_gen.go", "", "testcases", false)
+	if err != nil {
+		fmt.Println("TESTCONFIGENVPREFIX", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("TestConfigSnake", "TestConfigSnake", "t12/config.go", "t12/config_gen.go", "", "testcases", false, genconfig.Options{Naming: genconfig.NamingSnake})
+	if err != nil {
+		fmt.Println("TESTCONFIGSNAKE", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("TestConfigKebab", "TestConfigKebab", "t13/config.go", "t13/config_gen.go", "", "testcases", false, genconfig.Options{Naming: genconfig.NamingKebab})
+	if err != nil {
+		fmt.Println("TESTCONFIGKEBAB", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("TESTCONFIGALIASES", "TestConfigAliases", "t14/config.go", "t14/config_gen.go", "", "testcases", false, genconfig.Options{Dotenv: true, ConfigDir: true, Flags: true})
+	if err != nil {
+		fmt.Println("TESTCONFIGALIASES", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("App", "TestConfigSeparator", "t15/config.go", "t15/config_gen.go", "", "testcases", false, genconfig.Options{Naming: genconfig.NamingSnake, Separator: "__"})
+	if err != nil {
+		fmt.Println("TESTCONFIGSEPARATOR", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("TESTCONFIGCI", "TestConfigCaseInsensitive", "t16/config.go", "t16/config_gen.go", "", "testcases", false, genconfig.Options{CaseInsensitive: true})
+	if err != nil {
+		fmt.Println("TESTCONFIGCI", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("APP", "TestConfigInterpolation", "t17/config.go", "t17/config_gen.go", "", "testcases", false, genconfig.Options{Interpolation: true, Dotenv: true})
+	if err != nil {
+		fmt.Println("TESTCONFIGINTERPOLATION", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("APP", "TestConfigProfiles", "t18/config.go", "t18/config_gen.go", "", "testcases", false, genconfig.Options{Interpolation: true})
+	if err != nil {
+		fmt.Println("TESTCONFIGPROFILES", err)
+	}
+	err = genconfig.GenerateConfigLoader("APP", "TestConfigValidation", "t19/config.go", "t19/config_gen.go", "", "testcases", false)
+	if err != nil {
+		fmt.Println("TESTCONFIGVALIDATION", err)
+	}
+	err = genconfig.GenerateConfigLoader("APP", "TestConfigRequired", "t20/config.go", "t20/config_gen.go", "", "testcases", false)
+	if err != nil {
+		fmt.Println("TESTCONFIGREQUIRED", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("APP", "TestConfigEmptyUnset", "t21/config.go", "t21/config_gen.go", "", "testcases", false, genconfig.Options{EmptyIsUnset: true})
+	if err != nil {
+		fmt.Println("TESTCONFIGEMPTYUNSET", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("APP", "TestConfigValidate", "t22/config.go", "t22/config_gen.go", "", "testcases", false, genconfig.Options{ErrorReport: true})
+	if err != nil {
+		fmt.Println("TESTCONFIGVALIDATE", err)
+	}
+	err = genconfig.GenerateConfigLoader("APP", "TestConfigConditions", "t23/config.go", "t23/config_gen.go", "", "testcases", false)
+	if err != nil {
+		fmt.Println("TESTCONFIGCONDITIONS", err)
+	}
+	err = genconfig.GenerateConfigLoader("APP", "TestConfigSemantic", "t24/config.go", "t24/config_gen.go", "", "testcases", false)
+	if err != nil {
+		fmt.Println("TESTCONFIGSEMANTIC", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("API", "APIConfig", "t25/config.go", "t25/api_gen.go", "", "testcases", false, genconfig.Options{RuntimeFile: "t25/runtime_gen.go", Dotenv: true})
+	if err != nil {
+		fmt.Println("APICONFIG", err)
+	}
+	err = genconfig.GenerateConfigLoaderWithOptions("WORKER", "WorkerConfig", "t25/config.go", "t25/worker_gen.go", "", "testcases", false, genconfig.Options{RuntimeFile: "t25/runtime_gen.go", CaseInsensitive: true})
+	if err != nil {
+		fmt.Println("WORKERCONFIG", err)
+	}
 }